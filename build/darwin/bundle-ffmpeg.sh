@@ -1,5 +1,5 @@
 #!/bin/bash
-# Post-build script to bundle ffmpeg into the macOS .app
+# Post-build script to bundle ffmpeg and ffprobe into the macOS .app
 
 APP_PATH="$1"
 if [ -z "$APP_PATH" ]; then
@@ -9,15 +9,17 @@ fi
 
 SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
 PROJECT_ROOT="$(cd "$SCRIPT_DIR/../.." && pwd)"
-FFMPEG_SRC="$PROJECT_ROOT/bin/ffmpeg"
-FFMPEG_DST="$APP_PATH/Contents/Resources/ffmpeg"
+for TOOL in ffmpeg ffprobe; do
+    TOOL_SRC="$PROJECT_ROOT/bin/$TOOL"
+    TOOL_DST="$APP_PATH/Contents/Resources/$TOOL"
 
-if [ ! -f "$FFMPEG_SRC" ]; then
-    echo "Error: ffmpeg not found at $FFMPEG_SRC"
-    exit 1
-fi
+    if [ ! -f "$TOOL_SRC" ]; then
+        echo "Error: $TOOL not found at $TOOL_SRC"
+        exit 1
+    fi
 
-echo "Copying ffmpeg to $FFMPEG_DST"
-cp "$FFMPEG_SRC" "$FFMPEG_DST"
-chmod +x "$FFMPEG_DST"
+    echo "Copying $TOOL to $TOOL_DST"
+    cp "$TOOL_SRC" "$TOOL_DST"
+    chmod +x "$TOOL_DST"
+done
 echo "Done bundling ffmpeg"
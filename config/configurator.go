@@ -9,7 +9,7 @@ import (
 )
 
 type Configurator struct {
-	App adapters.App
+	App *adapters.App
 }
 
 func NewConfigurator() *Configurator {
@@ -17,7 +17,7 @@ func NewConfigurator() *Configurator {
 }
 
 func (c *Configurator) AddApp() *Configurator {
-	c.App = *adapters.NewApp()
+	c.App = adapters.NewApp()
 
 	return c
 }
@@ -32,8 +32,9 @@ func (c *Configurator) Build() *Configurator {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        c.App.Startup,
+		OnShutdown:       c.App.Shutdown,
 		Bind: []any{
-			&c.App,
+			c.App,
 		},
 	})
 
@@ -0,0 +1,6 @@
+package data
+
+import "embed"
+
+//go:embed migrations/*.sql
+var Migrations embed.FS
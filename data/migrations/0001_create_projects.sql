@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS projects (
+	id TEXT PRIMARY KEY,
+	title TEXT NOT NULL,
+	path TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_title ON projects(title);
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects(created_at);
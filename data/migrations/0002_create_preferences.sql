@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS preferences (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
@@ -2,48 +2,41 @@ package adapters
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/edlingao/viover/internal/viover/core"
-	"github.com/labstack/echo/v4"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
-	ctx            context.Context
-	Microphone     *core.Microphone
-	echo           *echo.Echo
-	store          *core.Store
-	currentProject *core.Project
+	ctx             context.Context
+	events          core.EventSink
+	Microphone      *core.Microphone
+	stopDeviceWatch func()
+	media           *MediaServer
+	store           *core.Store
+	currentProject  *core.Project
+	history         *core.History
 }
 
 func NewApp() *App {
-	e := echo.New()
-	videoGroup := e.Group("/videos")
-	videoGroup.GET("/*", VideoHandler)
-	audioGroup := e.Group("/audio")
-	audioGroup.GET("/*", AudioHandler)
-
-	go func() {
-		if err := e.Start(":8080"); err != nil {
-			log.Println("Echo server error:", err)
-		}
-	}()
-
-	return &App{
-		echo: e,
+	a := &App{}
+	media, err := NewMediaServer()
+	if err != nil {
+		log.Println("Error starting media server:", err)
 	}
+	a.media = media
+	return a
 }
 
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
-	a.Microphone = core.NewMicrophone(ctx)
+	a.events = NewWailsEventSink(ctx)
+	a.Microphone = core.NewMicrophone(a.events, core.NewMalgoBackend())
+	a.stopDeviceWatch = a.Microphone.WatchDevices(core.DefaultDeviceWatchInterval)
 	configDir, _ := os.UserConfigDir()
 	vioverDir := filepath.Join(configDir, "viover")
 	store, err := core.NewStore(vioverDir)
@@ -51,6 +44,26 @@ func (a *App) Startup(ctx context.Context) {
 		log.Println("Error creating store:", err)
 	}
 	a.store = store
+	if store != nil {
+		if _, err := store.CheckProjects(); err != nil {
+			log.Println("Error checking projects:", err)
+		}
+	}
+	a.applyPreferences(a.GetPreferences())
+}
+
+func (a *App) Shutdown(ctx context.Context) {
+	if a.stopDeviceWatch != nil {
+		a.stopDeviceWatch()
+	}
+	// Undo does not outlive the session, so neither does its trash.
+	if a.history != nil {
+		a.history.Clear()
+		a.history = nil
+	}
+	if a.media != nil {
+		a.media.Close()
+	}
 }
 
 func (a *App) ListDevices() []core.DeviceInfo {
@@ -58,7 +71,13 @@ func (a *App) ListDevices() []core.DeviceInfo {
 }
 
 func (a *App) SelectDevice(uuid string) core.DeviceInfo {
-	return a.Microphone.SelectDevice(uuid)
+	device := a.Microphone.SelectDevice(uuid)
+	if device.DevicesName != "" {
+		a.updatePreferences(func(prefs *core.Preferences) {
+			prefs.MicrophoneName = device.DevicesName
+		})
+	}
+	return device
 }
 
 func (a *App) GetSelectedDevice() core.DeviceInfo {
@@ -72,15 +91,48 @@ func (a *App) ListProjects() []core.ProjectMeta {
 	return a.store.ListProjects()
 }
 
+// CheckProjects rereads every registered project.json, refreshing the
+// health shown by ListProjects. Projects that fail to reconcile are logged
+// and still listed.
+func (a *App) CheckProjects() []core.ProjectMeta {
+	if a.store == nil {
+		return []core.ProjectMeta{}
+	}
+	projects, err := a.store.CheckProjects()
+	if err != nil {
+		log.Println("Error checking projects:", err)
+	}
+	return projects
+}
+
+// RediscoverProjects asks for a folder and registers or repoints any
+// projects found inside it.
+func (a *App) RediscoverProjects() ([]core.ProjectMeta, error) {
+	if a.store == nil {
+		return nil, nil
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Search for Projects",
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	return a.store.RediscoverProjects(dir)
+}
+
 func (a *App) CreateProject(title string) (*core.Project, error) {
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
 		Title:                "Select Project Location",
+		DefaultDirectory:     a.GetPreferences().ProjectRoot,
 		CanCreateDirectories: true,
 	})
 	if err != nil || dir == "" {
 		return nil, err
 	}
-	projectPath := filepath.Join(dir, sanitizeFilename(title))
+	a.updatePreferences(func(prefs *core.Preferences) {
+		prefs.ProjectRoot = dir
+	})
+	projectPath := filepath.Join(dir, core.SanitizeFilename(title))
 	if err := os.MkdirAll(projectPath, 0755); err != nil {
 		return nil, err
 	}
@@ -94,7 +146,7 @@ func (a *App) CreateProject(title string) (*core.Project, error) {
 	if err := a.store.AddProject(project); err != nil {
 		return nil, err
 	}
-	a.currentProject = project
+	a.setCurrentProject(project)
 	return project, nil
 }
 
@@ -107,37 +159,187 @@ func (a *App) OpenProject(id string) (*core.Project, error) {
 	if err != nil {
 		return nil, err
 	}
-	a.currentProject = project
+	// Audio left in the trash by a session that did not shut down cleanly
+	// can no longer be restored by undo.
+	if err := project.PurgeTrash(); err != nil {
+		log.Println("Error purging trash:", err)
+	}
+	if project.Video != nil && project.Video.Duration == 0 {
+		a.reprobeVideo(project)
+	}
+	a.setCurrentProject(project)
 	return project, nil
 }
 
+func (a *App) ListProjectBackups() []core.ProjectBackup {
+	if a.currentProject == nil {
+		return nil
+	}
+	return core.ListProjectBackups(a.currentProject.Path)
+}
+
+// RestoreProjectBackup rolls the current project back to one of its
+// previous saves.
+func (a *App) RestoreProjectBackup(index int) (*core.Project, error) {
+	if a.currentProject == nil {
+		return nil, nil
+	}
+	project, err := core.RestoreProjectBackup(a.currentProject.Path, index)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the trash: the restored save may refer to audio deleted since.
+	a.history = nil
+	a.setCurrentProject(project)
+	return project, nil
+}
+
+// RelocateProject asks for the folder a project was moved to and updates
+// its registration.
+func (a *App) RelocateProject(id string) (*core.Project, error) {
+	meta := a.store.GetProject(id)
+	if meta == nil {
+		return nil, nil
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Locate Project Folder",
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	project, err := core.LoadProject(dir)
+	if err != nil {
+		return nil, err
+	}
+	if project.ID != id {
+		return nil, fmt.Errorf("folder contains a different project: %s", project.Title)
+	}
+	if err := a.store.UpdateProjectPath(id, dir); err != nil {
+		return nil, err
+	}
+	if a.currentProject != nil && a.currentProject.ID == id {
+		a.setCurrentProject(project)
+	}
+	return project, nil
+}
+
+func (a *App) GetMissingMedia() []core.MissingMedia {
+	if a.currentProject == nil {
+		return nil
+	}
+	return a.currentProject.MissingMedia()
+}
+
+// RelinkMedia asks for a folder, searches it for the project's missing
+// video and recordings and copies what it finds into the project.
+func (a *App) RelinkMedia() (*core.RelinkResult, error) {
+	if a.currentProject == nil {
+		return nil, nil
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Search for Missing Media",
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	result, err := a.currentProject.FindMissingMedia(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Relinked) > 0 {
+		if err := a.execute(core.NewRelinkMediaCommand(result.Relinked)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// reprobeVideo fills in metadata for videos imported before probing existed.
+func (a *App) reprobeVideo(project *core.Project) {
+	probed, err := core.ProbeVideo(project.Video.FilePath)
+	if err != nil {
+		log.Println("Error probing video:", err)
+		return
+	}
+	probed.ID = project.Video.ID
+	probed.FileName = project.Video.FileName
+	probed.FilePath = project.Video.FilePath
+	probed.Hash = project.Video.Hash
+	probed.Thumbnail = project.Video.Thumbnail
+	project.SetVideo(probed)
+	if err := project.Save(); err != nil {
+		log.Println("Error saving project:", err)
+	}
+}
+
 func (a *App) GetCurrentProject() *core.Project {
 	return a.currentProject
 }
 
 func (a *App) UpdateProjectTitle(id, title string) error {
 	if a.currentProject != nil && a.currentProject.ID == id {
-		a.currentProject.Title = title
-		if err := a.currentProject.Save(); err != nil {
+		if err := a.execute(core.NewRenameProjectCommand(title)); err != nil {
 			return err
 		}
+	} else if meta := a.store.GetProject(id); meta != nil {
+		return a.store.RenameProject(*meta, title)
 	}
 	return a.store.UpdateProject(id, title)
 }
 
 func (a *App) DeleteProject(id string) error {
 	meta := a.store.GetProject(id)
-	if meta != nil {
+	if meta != nil && a.store.ProjectStatus(*meta) == core.ProjectOK {
+		// Only delete the folder if it still holds this project; a moved
+		// project's old path may now contain something else.
 		os.RemoveAll(meta.Path)
 	}
 	if a.currentProject != nil && a.currentProject.ID == id {
-		a.currentProject = nil
+		a.setCurrentProject(nil)
 	}
 	return a.store.RemoveProject(id)
 }
 
 func (a *App) CloseProject() {
-	a.currentProject = nil
+	a.setCurrentProject(nil)
+}
+
+// setCurrentProject switches the open project and limits the media server to
+// its directory and recordings.
+func (a *App) setCurrentProject(project *core.Project) {
+	if a.history != nil {
+		a.history.Clear()
+		a.history = nil
+	}
+	a.currentProject = project
+	if project != nil {
+		a.history = core.NewHistory(project, core.DefaultHistoryDepth)
+	}
+	if a.media == nil {
+		return
+	}
+	if project == nil {
+		a.media.SetRoot("")
+	} else {
+		a.media.SetRoot(project.Path)
+	}
+	a.syncMedia()
+}
+
+// syncMedia hands the media server the current project's recording paths.
+// The server runs on its own goroutine, so it never reads the project
+// directly.
+func (a *App) syncMedia() {
+	if a.media == nil {
+		return
+	}
+	paths := make(map[string]string)
+	if a.currentProject != nil {
+		for _, r := range a.currentProject.Recordings {
+			paths[r.ID] = r.FilePath
+		}
+	}
+	a.media.SetRecordings(paths)
 }
 
 func (a *App) SelectVideo() (*core.Video, error) {
@@ -153,17 +355,22 @@ func (a *App) SelectVideo() (*core.Video, error) {
 	}
 	destPath := filepath.Join(a.currentProject.Path, filepath.Base(selection))
 	if selection != destPath {
-		if err := copyFile(selection, destPath); err != nil {
+		if err := core.CopyFile(selection, destPath); err != nil {
 			return nil, err
 		}
 	}
-	video := &core.Video{
-		ID:       filepath.Base(destPath),
-		FileName: filepath.Base(destPath),
-		FilePath: destPath,
+	video, err := core.ProbeVideo(destPath)
+	if err != nil {
+		log.Println("Error probing video:", err)
+		video = &core.Video{}
+	}
+	video.ID = filepath.Base(destPath)
+	video.FileName = filepath.Base(destPath)
+	video.FilePath = destPath
+	if hash, err := core.HashFile(destPath); err == nil {
+		video.Hash = hash
 	}
-	a.currentProject.SetVideo(video)
-	if err := a.currentProject.Save(); err != nil {
+	if err := a.execute(core.NewSetVideoCommand(video)); err != nil {
 		return nil, err
 	}
 	return video, nil
@@ -173,7 +380,49 @@ func (a *App) GetVideoURL() string {
 	if a.currentProject == nil || a.currentProject.Video == nil {
 		return ""
 	}
-	return "http://localhost:8080/videos/" + a.currentProject.Video.FilePath
+	if a.media == nil {
+		return ""
+	}
+	rel, err := filepath.Rel(a.currentProject.Path, a.currentProject.Video.FilePath)
+	if err != nil {
+		return ""
+	}
+	return a.media.URL("videos", rel)
+}
+
+func (a *App) FormatTimecode(seconds float64) string {
+	if a.currentProject == nil {
+		return core.SecondsToTimecode(seconds, core.DefaultFrameRate).String()
+	}
+	return a.currentProject.Timecode(seconds).String()
+}
+
+func (a *App) ParseTimecode(timecode string) (float64, error) {
+	if a.currentProject == nil {
+		tc, err := core.ParseTimecode(timecode, core.DefaultFrameRate)
+		if err != nil {
+			return 0, err
+		}
+		return tc.ToSeconds(core.DefaultFrameRate), nil
+	}
+	return a.currentProject.TimelineSeconds(timecode)
+}
+
+func (a *App) SetStartTimecode(timecode string) error {
+	if a.currentProject == nil {
+		return nil
+	}
+	return a.execute(core.NewSetStartTimecodeCommand(timecode))
+}
+
+func (a *App) SetAudioFormat(format core.AudioFormat) error {
+	if a.currentProject == nil {
+		return nil
+	}
+	if a.Microphone.IsRecording() {
+		return fmt.Errorf("cannot change the audio format while recording")
+	}
+	return a.execute(core.NewSetAudioFormatCommand(format))
 }
 
 func (a *App) AddCharacter(name, color string) (*core.Character, error) {
@@ -181,8 +430,7 @@ func (a *App) AddCharacter(name, color string) (*core.Character, error) {
 		return nil, nil
 	}
 	character := core.NewCharacter(name, color)
-	a.currentProject.AddCharacter(character)
-	if err := a.currentProject.Save(); err != nil {
+	if err := a.execute(core.NewAddCharacterCommand(character)); err != nil {
 		return nil, err
 	}
 	return character, nil
@@ -192,34 +440,56 @@ func (a *App) UpdateCharacter(id, name, color string) error {
 	if a.currentProject == nil {
 		return nil
 	}
-	a.currentProject.UpdateCharacter(id, name, color)
-	return a.currentProject.Save()
+	return a.execute(core.NewUpdateCharacterCommand(id, name, color))
 }
 
 func (a *App) DeleteCharacter(id string) error {
 	if a.currentProject == nil {
 		return nil
 	}
-	for _, r := range a.currentProject.Recordings {
-		if r.CharacterID == id {
-			os.Remove(r.FilePath)
-		}
-	}
-	a.currentProject.RemoveCharacter(id)
-	return a.currentProject.Save()
+	return a.execute(core.NewRemoveCharacterCommand(id))
+}
+
+type RecordOptions struct {
+	Timecode float64 `json:"timecode"`
+	Punch    bool    `json:"punch"`
+	InPoint  float64 `json:"inPoint"`
+	OutPoint float64 `json:"outPoint"`
+	PreRoll  float64 `json:"preRoll"`
+	PostRoll float64 `json:"postRoll"`
 }
 
 func (a *App) RecordAudio(characterID string, timecode float64) (*core.Recording, error) {
+	return a.RecordAudioWithOptions(characterID, RecordOptions{Timecode: timecode})
+}
+
+func (a *App) RecordAudioWithOptions(characterID string, opts RecordOptions) (*core.Recording, error) {
 	if a.currentProject == nil {
 		return nil, nil
 	}
 	recordingPath := filepath.Join(a.currentProject.Path, "recordings")
-	recording, err := a.Microphone.RecordToFile(recordingPath, characterID, timecode)
+	if err := a.Microphone.SetAudioFormat(a.currentProject.Format()); err != nil {
+		return nil, err
+	}
+	var recording *core.Recording
+	var err error
+	if opts.Punch {
+		recording, err = a.Microphone.RecordPunch(recordingPath, characterID, core.Punch{
+			In:       opts.InPoint,
+			Out:      opts.OutPoint,
+			PreRoll:  opts.PreRoll,
+			PostRoll: opts.PostRoll,
+		})
+	} else {
+		recording, err = a.Microphone.RecordToFile(recordingPath, characterID, opts.Timecode)
+	}
 	if err != nil {
 		return nil, err
 	}
-	a.currentProject.AddRecording(recording)
-	if err := a.currentProject.Save(); err != nil {
+	if hash, err := core.HashFile(recording.FilePath); err == nil {
+		recording.Hash = hash
+	}
+	if err := a.execute(core.NewAddRecordingCommand(recording)); err != nil {
 		return nil, err
 	}
 	return recording, nil
@@ -233,14 +503,25 @@ func (a *App) DeleteRecording(id string) error {
 	if a.currentProject == nil {
 		return nil
 	}
-	for _, r := range a.currentProject.Recordings {
-		if r.ID == id {
-			os.Remove(r.FilePath)
-			break
-		}
+	return a.execute(core.NewRemoveRecordingCommand(id))
+}
+
+func (a *App) GetTakes(recordingID string) []*core.Recording {
+	if a.currentProject == nil {
+		return nil
 	}
-	a.currentProject.RemoveRecording(id)
-	return a.currentProject.Save()
+	r := a.currentProject.GetRecording(recordingID)
+	if r == nil {
+		return nil
+	}
+	return a.currentProject.Takes(r.SlotID)
+}
+
+func (a *App) SetActiveTake(recordingID string) error {
+	if a.currentProject == nil {
+		return nil
+	}
+	return a.execute(core.NewSetActiveTakeCommand(recordingID))
 }
 
 func (a *App) GetRecordingWaveform(recordingID string) ([]int, error) {
@@ -259,170 +540,89 @@ func (a *App) UpdateRecordingTimecode(recordingID string, newTimecode float64) e
 	if a.currentProject == nil {
 		return nil
 	}
-	a.currentProject.UpdateRecordingTimecode(recordingID, newTimecode)
-	return a.currentProject.Save()
+	return a.execute(core.NewMoveRecordingCommand(recordingID, newTimecode))
 }
 
 func (a *App) UpdateRecordingVolume(recordingID string, volume float64) error {
 	if a.currentProject == nil {
 		return nil
 	}
-	a.currentProject.UpdateRecordingVolume(recordingID, volume)
-	return a.currentProject.Save()
+	return a.execute(core.NewRecordingVolumeCommand(recordingID, volume))
 }
 
 func (a *App) UpdateRecordingGain(recordingID string, gainDB float64) error {
 	if a.currentProject == nil {
 		return nil
 	}
-	a.currentProject.UpdateRecordingGain(recordingID, gainDB)
-	return a.currentProject.Save()
+	return a.execute(core.NewRecordingGainCommand(recordingID, gainDB))
 }
 
-func (a *App) SetMicrophoneGain(gainDB float64) {
-	a.Microphone.SetInputGain(gainDB)
-}
-
-func (a *App) GetMicrophoneGain() float64 {
-	return a.Microphone.GetInputGain()
+// execute applies a command to the current project through its undo
+// history and saves the result.
+func (a *App) execute(cmd core.Command) error {
+	if err := a.history.Execute(cmd); err != nil {
+		return err
+	}
+	a.syncMedia()
+	return a.currentProject.Save()
 }
 
-// GetAudioData returns base64-encoded audio data (Wails has issues with large byte arrays)
-func (a *App) GetAudioData(recordingID string) (string, error) {
-	log.Printf("[GetAudioData] Called with recordingID: %s", recordingID)
-
+func (a *App) Undo() (*core.Project, error) {
 	if a.currentProject == nil {
-		log.Println("[GetAudioData] ERROR: No current project!")
-		return "", nil
+		return nil, nil
 	}
-
-	for _, r := range a.currentProject.Recordings {
-		if r.ID == recordingID {
-			data, err := os.ReadFile(r.FilePath)
-			if err != nil {
-				log.Printf("[GetAudioData] ERROR: %v", err)
-				return "", err
-			}
-
-			// Return base64-encoded data
-			encoded := base64.StdEncoding.EncodeToString(data)
-			log.Printf("[GetAudioData] SUCCESS! Read %d bytes, encoded to %d chars", len(data), len(encoded))
-			return encoded, nil
-		}
+	if _, err := a.history.Undo(); err != nil {
+		return nil, err
 	}
-
-	log.Printf("[GetAudioData] Recording not found: %s", recordingID)
-	return "", nil
+	return a.currentProject, a.saveAfterHistory()
 }
 
-type ExportOptions struct {
-	Format           string             `json:"format"`
-	CharacterVolumes map[string]float64 `json:"characterVolumes"`
-	MasterVolume     float64            `json:"masterVolume"`
-}
-
-func (a *App) ExportRecordings(format string) (string, error) {
-	return a.ExportRecordingsWithOptions(ExportOptions{
-		Format:           format,
-		CharacterVolumes: make(map[string]float64),
-		MasterVolume:     1.0,
-	})
-}
-
-func (a *App) ExportRecordingsWithOptions(opts ExportOptions) (string, error) {
+func (a *App) Redo() (*core.Project, error) {
 	if a.currentProject == nil {
-		return "", nil
-	}
-	if opts.Format != "wav" && opts.Format != "mp3" && opts.Format != "flac" {
-		return "", fmt.Errorf("unsupported format: %s", opts.Format)
-	}
-	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title:                "Select Export Location",
-		CanCreateDirectories: true,
-	})
-	if err != nil || dir == "" {
-		return "", err
-	}
-	exportDir := filepath.Join(dir, a.currentProject.Title+"_export")
-	if err := os.MkdirAll(exportDir, 0755); err != nil {
-		return "", err
+		return nil, nil
 	}
-	for _, r := range a.currentProject.Recordings {
-		var characterName string
-		charVol := 1.0
-		for _, c := range a.currentProject.Characters {
-			if c.ID == r.CharacterID {
-				characterName = c.Name
-				if v, ok := opts.CharacterVolumes[c.ID]; ok {
-					charVol = v
-				}
-				break
-			}
-		}
-		if characterName == "" {
-			characterName = "Unknown"
-		}
-		recVol := r.Volume
-		if recVol == 0 {
-			recVol = 1.0
-		}
-		gainLinear := core.DBToLinear(r.GainDB)
-		finalVol := opts.MasterVolume * charVol * recVol * gainLinear
-		destName := sanitizeFilename(characterName) + "_" + formatTimecode(r.Timecode) + "." + opts.Format
-		destPath := filepath.Join(exportDir, destName)
-		if err := core.ExportAudio(r.FilePath, destPath, opts.Format, finalVol); err != nil {
-			log.Println("Export error:", err)
-		}
+	if _, err := a.history.Redo(); err != nil {
+		return nil, err
 	}
-	return exportDir, nil
+	return a.currentProject, a.saveAfterHistory()
 }
 
-func VideoHandler(c echo.Context) error {
-	path := c.Param("*")
-	return c.File(path)
-}
-
-func AudioHandler(c echo.Context) error {
-	path := c.Param("*")
-	return c.File(path)
+func (a *App) History() *core.HistoryState {
+	if a.currentProject == nil {
+		return nil
+	}
+	state := a.history.State()
+	return &state
 }
 
-func copyFile(src, dst string) error {
-	source, err := os.Open(src)
-	if err != nil {
+// saveAfterHistory saves the project and keeps the store's title in sync,
+// since undo and redo can rename the project.
+func (a *App) saveAfterHistory() error {
+	a.syncMedia()
+	if err := a.currentProject.Save(); err != nil {
 		return err
 	}
-	defer source.Close()
-	dest, err := os.Create(dst)
-	if err != nil {
-		return err
+	if a.store == nil {
+		return nil
 	}
-	defer dest.Close()
-	_, err = io.Copy(dest, source)
-	return err
+	return a.store.UpdateProject(a.currentProject.ID, a.currentProject.Title)
 }
 
-func sanitizeFilename(name string) string {
-	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
-	return replacer.Replace(name)
+func (a *App) SetMicrophoneGain(gainDB float64) {
+	a.Microphone.SetInputGain(gainDB)
+	a.updatePreferences(func(prefs *core.Preferences) {
+		prefs.InputGainDB = a.Microphone.GetInputGain()
+	})
 }
 
-func formatTimecode(seconds float64) string {
-	mins := int(seconds) / 60
-	secs := int(seconds) % 60
-	frames := int((seconds - float64(int(seconds))) * 30)
-	return strings.ReplaceAll(strings.ReplaceAll(
-		strings.Join([]string{
-			padInt(mins),
-			padInt(secs),
-			padInt(frames),
-		}, "-"),
-		":", "-"), " ", "")
+func (a *App) GetMicrophoneGain() float64 {
+	return a.Microphone.GetInputGain()
 }
 
-func padInt(n int) string {
-	if n < 10 {
-		return "0" + string(rune('0'+n))
+// GetAudioURL returns the media server URL for a recording, streamed by ID.
+func (a *App) GetAudioURL(recordingID string) string {
+	if a.media == nil || a.currentProject == nil || a.currentProject.GetRecording(recordingID) == nil {
+		return ""
 	}
-	return string(rune('0'+n/10)) + string(rune('0'+n%10))
+	return a.media.URL("audio", recordingID)
 }
@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestBindingsMatchApp fails when a bound App method was added, removed or
+// changed arity without regenerating web/app/wailsjs.
+func TestBindingsMatchApp(t *testing.T) {
+	data, err := os.ReadFile("../../../web/app/wailsjs/go/adapters/App.d.ts")
+	if err != nil {
+		t.Fatalf("Failed to read bindings: %v", err)
+	}
+	bound := make(map[string]int)
+	for _, m := range regexp.MustCompile(`export function (\w+)\(([^)]*)\)`).FindAllStringSubmatch(string(data), -1) {
+		bound[m[1]] = strings.Count(m[2], "arg")
+	}
+
+	// Startup and Shutdown are lifecycle hooks, which Wails does not bind.
+	lifecycle := map[string]bool{"Startup": true, "Shutdown": true}
+	app := reflect.TypeOf(&App{})
+	for i := 0; i < app.NumMethod(); i++ {
+		m := app.Method(i)
+		if lifecycle[m.Name] {
+			continue
+		}
+		args, ok := bound[m.Name]
+		if !ok {
+			t.Errorf("Expected a binding for App.%s, regenerate web/app/wailsjs", m.Name)
+			continue
+		}
+		if want := m.Type.NumIn() - 1; args != want {
+			t.Errorf("Expected the App.%s binding to take %d arguments, got %d", m.Name, want, args)
+		}
+		delete(bound, m.Name)
+	}
+	for name := range bound {
+		t.Errorf("Expected no binding for missing method App.%s", name)
+	}
+}
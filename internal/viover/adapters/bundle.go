@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edlingao/viover/internal/viover/core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ExportProjectBundle packs the current project into a .viover archive and
+// returns its path.
+func (a *App) ExportProjectBundle(opts core.BundleOptions) (string, error) {
+	if a.currentProject == nil {
+		return "", nil
+	}
+	dst, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Project Bundle",
+		DefaultFilename: core.SanitizeFilename(a.currentProject.Title) + core.BundleExtension,
+		Filters:         []runtime.FileFilter{{DisplayName: "viover Bundles", Pattern: "*" + core.BundleExtension}},
+	})
+	if err != nil || dst == "" {
+		return "", err
+	}
+	if err := core.PackProject(a.currentProject, dst, opts); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// ImportProjectBundle unpacks a .viover archive into a chosen folder and
+// registers it. With preserveID the project keeps its original ID, which
+// fails if that project is already registered here.
+func (a *App) ImportProjectBundle(preserveID bool) (*core.Project, error) {
+	src, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Project Bundle",
+		Filters: []runtime.FileFilter{{DisplayName: "viover Bundles", Pattern: "*" + core.BundleExtension}},
+	})
+	if err != nil || src == "" {
+		return nil, err
+	}
+	bundled, err := core.ReadBundleProject(src)
+	if err != nil {
+		return nil, err
+	}
+	if preserveID && a.store.GetProject(bundled.ID) != nil {
+		return nil, fmt.Errorf("project is already registered: %s", bundled.Title)
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select Project Location",
+		CanCreateDirectories: true,
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	project, err := core.UnpackProject(src, dir, preserveID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.store.AddProject(project); err != nil {
+		os.RemoveAll(project.Path)
+		return nil, err
+	}
+	a.setCurrentProject(project)
+	return project, nil
+}
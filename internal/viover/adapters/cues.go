@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"fmt"
+
+	"github.com/edlingao/viover/internal/viover/core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ImportSubtitles reads an SRT, WebVTT or ASS/SSA file into the project's
+// cue list, creating characters for speakers that do not exist yet. With
+// replace the existing cues are dropped first.
+func (a *App) ImportSubtitles(replace bool) ([]*core.Cue, error) {
+	if a.currentProject == nil {
+		return nil, nil
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Subtitles",
+		Filters: []runtime.FileFilter{{DisplayName: "Subtitle Files", Pattern: "*.srt;*.vtt;*.ass;*.ssa"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	cues, err := core.ParseSubtitleFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.execute(core.NewImportCuesCommand(cues, replace)); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+// ImportScript reads a Fountain or "CHARACTER: line" screenplay, creating a
+// character for every speaker and an unplaced cue for every line of
+// dialogue.
+func (a *App) ImportScript(replace bool) ([]*core.Cue, error) {
+	if a.currentProject == nil {
+		return nil, nil
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Script",
+		Filters: []runtime.FileFilter{{DisplayName: "Scripts", Pattern: "*.fountain;*.spmd;*.txt"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	cues, err := core.ParseScriptFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.execute(core.NewImportCuesCommand(cues, replace)); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+// PlaceCue puts a cue on the timeline at start, in seconds.
+func (a *App) PlaceCue(cueID string, start float64) error {
+	if a.currentProject == nil {
+		return nil
+	}
+	return a.execute(core.NewPlaceCueCommand(cueID, start))
+}
+
+// ExportSubtitles writes one subtitle event per active take to an SRT,
+// WebVTT or ASS file and returns its path.
+func (a *App) ExportSubtitles(format string) (string, error) {
+	if a.currentProject == nil {
+		return "", nil
+	}
+	if format != core.SubtitleSRT && format != core.SubtitleVTT && format != core.SubtitleASS {
+		return "", fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+	dst, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export Subtitles",
+		DefaultDirectory: a.GetPreferences().ExportDir,
+		DefaultFilename:  core.SanitizeFilename(a.currentProject.Title) + "." + format,
+		Filters:          []runtime.FileFilter{{DisplayName: "Subtitle Files", Pattern: "*." + format}},
+	})
+	if err != nil || dst == "" {
+		return "", err
+	}
+	if err := a.currentProject.ExportSubtitles(dst, format); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// ExportCueSheet writes the project's ADR cue sheet as CSV, HTML or PDF and
+// returns its path.
+func (a *App) ExportCueSheet(format string) (string, error) {
+	if a.currentProject == nil {
+		return "", nil
+	}
+	if format != core.CueSheetCSV && format != core.CueSheetHTML && format != core.CueSheetPDF {
+		return "", fmt.Errorf("unsupported cue sheet format: %s", format)
+	}
+	dst, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Export Cue Sheet",
+		DefaultDirectory: a.GetPreferences().ExportDir,
+		DefaultFilename:  core.SanitizeFilename(a.currentProject.Title) + " cue sheet." + format,
+		Filters:          []runtime.FileFilter{{DisplayName: "Cue Sheet", Pattern: "*." + format}},
+	})
+	if err != nil || dst == "" {
+		return "", err
+	}
+	if err := a.currentProject.ExportCueSheet(dst, format); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
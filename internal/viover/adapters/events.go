@@ -0,0 +1,22 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/edlingao/viover/internal/viover/core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WailsEventSink forwards core events to the frontend through the Wails
+// runtime, using the event struct as the payload.
+type WailsEventSink struct {
+	ctx context.Context
+}
+
+func NewWailsEventSink(ctx context.Context) *WailsEventSink {
+	return &WailsEventSink{ctx: ctx}
+}
+
+func (s *WailsEventSink) Emit(event core.Event) {
+	runtime.EventsEmit(s.ctx, event.EventName(), event)
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edlingao/viover/internal/viover/core"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	ExportActiveTakes = "active"
+	ExportAllTakes    = "all"
+)
+
+const (
+	ExportModeFiles   = "files"
+	ExportModeMixdown = "mixdown"
+	ExportModeStems   = "stems"
+	ExportModeVideo   = "video"
+)
+
+// ExportOptions configures an export. MasterVolume is optional: when it is
+// nil the saved master volume preference is used, and when it is set it
+// becomes the new preference.
+type ExportOptions struct {
+	Format           string             `json:"format"`
+	CharacterVolumes map[string]float64 `json:"characterVolumes"`
+	MasterVolume     *float64           `json:"masterVolume,omitempty"`
+	Takes            string             `json:"takes"`
+	Mode             string             `json:"mode"`
+	VideoAudio       string             `json:"videoAudio"`
+	OriginalLevelDB  float64            `json:"originalLevelDb"`
+}
+
+func (a *App) ExportRecordings(format string) (string, error) {
+	return a.ExportRecordingsWithOptions(ExportOptions{
+		Format:           format,
+		CharacterVolumes: make(map[string]float64),
+	})
+}
+
+func (a *App) ExportRecordingsWithOptions(opts ExportOptions) (string, error) {
+	if a.currentProject == nil {
+		return "", nil
+	}
+	prefs := a.GetPreferences()
+	if opts.Format == "" {
+		opts.Format = prefs.ExportFormat
+	}
+	if opts.Format != "wav" && opts.Format != "mp3" && opts.Format != "flac" {
+		return "", fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Select Export Location",
+		DefaultDirectory:     prefs.ExportDir,
+		CanCreateDirectories: true,
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	a.updatePreferences(func(prefs *core.Preferences) {
+		prefs.ExportDir = dir
+		prefs.ExportFormat = opts.Format
+		if opts.MasterVolume != nil {
+			prefs.MasterVolume = *opts.MasterVolume
+		}
+	})
+	if opts.MasterVolume == nil {
+		opts.MasterVolume = &prefs.MasterVolume
+	}
+	exportDir := filepath.Join(dir, a.currentProject.Title+"_export")
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return "", err
+	}
+	switch opts.Mode {
+	case ExportModeMixdown:
+		return a.exportMixdown(exportDir, opts)
+	case ExportModeStems:
+		return a.exportStems(exportDir, opts)
+	case ExportModeVideo:
+		return a.exportVideo(exportDir, opts)
+	default:
+		return a.exportFiles(exportDir, opts)
+	}
+}
+
+func (a *App) exportFiles(exportDir string, opts ExportOptions) (string, error) {
+	recordings := a.currentProject.ActiveRecordings()
+	if opts.Takes == ExportAllTakes {
+		recordings = a.currentProject.Recordings
+	}
+	for _, r := range recordings {
+		var characterName string
+		charVol := 1.0
+		for _, c := range a.currentProject.Characters {
+			if c.ID == r.CharacterID {
+				characterName = c.Name
+				if v, ok := opts.CharacterVolumes[c.ID]; ok {
+					charVol = v
+				}
+				break
+			}
+		}
+		if characterName == "" {
+			characterName = "Unknown"
+		}
+		finalVol := *opts.MasterVolume * charVol * r.LinearGain()
+		destName := core.SanitizeFilename(characterName) + "_" + a.currentProject.Timecode(r.Timecode).Filename()
+		if opts.Takes == ExportAllTakes {
+			destName += fmt.Sprintf("_take%d", r.Take)
+		}
+		destName += "." + opts.Format
+		destPath := filepath.Join(exportDir, destName)
+		if err := core.ExportAudio(r.FilePath, destPath, opts.Format, finalVol, a.currentProject.Format()); err != nil {
+			log.Println("Export error:", err)
+		}
+	}
+	return exportDir, nil
+}
+
+func (a *App) exportMixdown(exportDir string, opts ExportOptions) (string, error) {
+	destPath := filepath.Join(exportDir, core.SanitizeFilename(a.currentProject.Title)+"_mixdown."+opts.Format)
+	err := a.currentProject.RenderMix(core.MixOptions{
+		CharacterVolumes: opts.CharacterVolumes,
+		MasterVolume:     *opts.MasterVolume,
+	}, destPath, opts.Format)
+	if err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+func (a *App) exportStems(exportDir string, opts ExportOptions) (string, error) {
+	_, err := a.currentProject.RenderStems(core.MixOptions{
+		CharacterVolumes: opts.CharacterVolumes,
+		MasterVolume:     *opts.MasterVolume,
+	}, exportDir, opts.Format)
+	if err != nil {
+		return "", err
+	}
+	return exportDir, nil
+}
+
+func (a *App) exportVideo(exportDir string, opts ExportOptions) (string, error) {
+	video := a.currentProject.Video
+	if video == nil {
+		return "", fmt.Errorf("project has no video")
+	}
+	codec, duration := video.Codec, video.Duration
+	if codec == "" || duration <= 0 {
+		if probed, err := core.ProbeVideo(video.FilePath); err == nil {
+			codec, duration = probed.Codec, probed.Duration
+		}
+	}
+	voicePath := filepath.Join(exportDir, ".voice.tmp.wav")
+	defer os.Remove(voicePath)
+	err := a.currentProject.RenderMix(core.MixOptions{
+		Length:           duration,
+		CharacterVolumes: opts.CharacterVolumes,
+		MasterVolume:     *opts.MasterVolume,
+	}, voicePath, "wav")
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(video.FileName, filepath.Ext(video.FileName))
+	destPath := filepath.Join(exportDir, core.SanitizeFilename(base)+"_dub.mp4")
+	mode := opts.VideoAudio
+	if mode == core.MuxMix && video.AudioStreams != nil && !video.HasAudio() {
+		mode = core.MuxReplace
+	}
+	err = core.MuxVideo(video.FilePath, voicePath, destPath, core.MuxOptions{
+		Mode:            mode,
+		OriginalLevelDB: opts.OriginalLevelDB,
+		SampleRate:      a.currentProject.Format().SampleRate,
+		VideoCodec:      codec,
+		Duration:        duration,
+	})
+	if err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
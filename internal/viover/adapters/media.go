@@ -0,0 +1,246 @@
+package adapters
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/edlingao/viover/internal/viover/core"
+	"github.com/labstack/echo/v4"
+)
+
+const mediaTokenHeader = "X-Viover-Token"
+
+// MediaServer serves project media to the webview. It listens on a random
+// loopback port, requires a per-session token and only serves files inside
+// the current project directory.
+type MediaServer struct {
+	echo       *echo.Echo
+	baseURL    string
+	token      string
+	mu         sync.RWMutex
+	root       string
+	recordings map[string]string
+}
+
+func NewMediaServer() (*MediaServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Listener = listener
+	s := &MediaServer{
+		echo:       e,
+		baseURL:    "http://" + listener.Addr().String(),
+		token:      hex.EncodeToString(tokenBytes),
+		recordings: make(map[string]string),
+	}
+	e.Use(allowCrossOrigin, s.requireToken)
+	e.GET("/videos/*", s.VideoHandler)
+	e.GET("/audio/:id", s.AudioHandler)
+
+	go func() {
+		if err := e.Start(""); err != nil && err != http.ErrServerClosed {
+			log.Println("Media server error:", err)
+		}
+	}()
+	return s, nil
+}
+
+// SetRoot restricts the server to files below dir. An empty dir serves
+// nothing.
+func (s *MediaServer) SetRoot(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.root = dir
+}
+
+// SetRecordings replaces the recording ID to file path map the audio
+// endpoint serves from. The map is copied, so the caller keeps ownership.
+func (s *MediaServer) SetRecordings(paths map[string]string) {
+	recordings := make(map[string]string, len(paths))
+	for id, path := range paths {
+		recordings[id] = path
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordings = recordings
+}
+
+func (s *MediaServer) recordingPath(id string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recordings[id]
+}
+
+// URL returns the tokenized URL for a file relative to the project root.
+func (s *MediaServer) URL(kind, relPath string) string {
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/%s/%s?token=%s", s.baseURL, kind, strings.Join(segments, "/"), s.token)
+}
+
+// allowCrossOrigin lets the webview route media through Web Audio, which
+// needs CORS headers on cross-origin sources.
+func allowCrossOrigin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+		return next(c)
+	}
+}
+
+func (s *MediaServer) requireToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.QueryParam("token")
+		if token == "" {
+			token = c.Request().Header.Get(mediaTokenHeader)
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			return echo.ErrForbidden
+		}
+		return next(c)
+	}
+}
+
+// resolve maps a request path onto a file inside the project root.
+func (s *MediaServer) resolve(relPath string) (string, error) {
+	s.mu.RLock()
+	root := s.root
+	s.mu.RUnlock()
+	if root == "" {
+		return "", echo.ErrNotFound
+	}
+	unescaped, err := url.PathUnescape(relPath)
+	if err != nil {
+		return "", echo.ErrBadRequest
+	}
+	return s.contain(filepath.Join(root, filepath.FromSlash(unescaped)))
+}
+
+// contain rejects paths outside the project root, including through
+// symlinks.
+func (s *MediaServer) contain(path string) (string, error) {
+	s.mu.RLock()
+	root := s.root
+	s.mu.RUnlock()
+	if root == "" {
+		return "", echo.ErrNotFound
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", echo.ErrNotFound
+	}
+	full, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", echo.ErrNotFound
+	}
+	rel, err := filepath.Rel(realRoot, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", echo.ErrForbidden
+	}
+	return full, nil
+}
+
+func (s *MediaServer) VideoHandler(c echo.Context) error {
+	path, err := s.resolve(c.Param("*"))
+	if err != nil {
+		return err
+	}
+	return serveFile(c, path)
+}
+
+// AudioHandler serves a recording by ID. With ?decode=wav, FLAC and MP3
+// sources are decoded to WAV on the fly.
+func (s *MediaServer) AudioHandler(c echo.Context) error {
+	recordingPath := s.recordingPath(c.Param("id"))
+	if recordingPath == "" {
+		return echo.ErrNotFound
+	}
+	path, err := s.contain(recordingPath)
+	if err != nil {
+		return err
+	}
+	if c.QueryParam("decode") == "wav" && strings.ToLower(filepath.Ext(path)) != ".wav" {
+		return serveDecoded(c, path)
+	}
+	return serveFile(c, path)
+}
+
+var mediaContentTypes = map[string]string{
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".mp3":  "audio/mpeg",
+	".mp4":  "video/mp4",
+	".mov":  "video/quicktime",
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+	".avi":  "video/x-msvideo",
+}
+
+func mediaETag(info os.FileInfo, suffix string) string {
+	return fmt.Sprintf(`"%x-%x%s"`, info.ModTime().UnixNano(), info.Size(), suffix)
+}
+
+// serveFile serves path with Range, ETag and conditional request support.
+func serveFile(c echo.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return echo.ErrNotFound
+	}
+	header := c.Response().Header()
+	header.Set("ETag", mediaETag(info, ""))
+	if contentType, ok := mediaContentTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		header.Set(echo.HeaderContentType, contentType)
+	}
+	http.ServeContent(c.Response(), c.Request(), info.Name(), info.ModTime(), f)
+	return nil
+}
+
+func serveDecoded(c echo.Context, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	etag := mediaETag(info, "-wav")
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	header.Set(echo.HeaderContentType, "audio/wav")
+	header.Set("Accept-Ranges", "none")
+	c.Response().WriteHeader(http.StatusOK)
+	if err := core.DecodeToWAV(path, c.Response()); err != nil {
+		log.Println("Error decoding audio:", err)
+	}
+	return nil
+}
+
+func (s *MediaServer) Close() error {
+	return s.echo.Close()
+}
@@ -0,0 +1,172 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestMediaServer serves a project folder holding video.mp4 and
+// recordings/take.wav, next to a secret.txt outside of it.
+func newTestMediaServer(t *testing.T) (*MediaServer, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+	os.MkdirAll(filepath.Join(root, "recordings"), 0755)
+	os.WriteFile(filepath.Join(root, "video.mp4"), []byte("video"), 0644)
+	os.WriteFile(filepath.Join(root, "recordings", "take.wav"), []byte("take"), 0644)
+	secret := filepath.Join(dir, "secret.txt")
+	os.WriteFile(secret, []byte("secret"), 0644)
+
+	s, err := NewMediaServer()
+	if err != nil {
+		t.Fatalf("Failed to start media server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	s.SetRoot(root)
+	s.SetRecordings(map[string]string{
+		"take":   filepath.Join(root, "recordings", "take.wav"),
+		"secret": secret,
+	})
+	return s, root, secret
+}
+
+func serveMedia(s *MediaServer, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMediaServerRequiresToken(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+
+	if rec := serveMedia(s, "/videos/video.mp4", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without a token, got %d", rec.Code)
+	}
+	if rec := serveMedia(s, "/videos/video.mp4?token=wrong", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 with a wrong token, got %d", rec.Code)
+	}
+	rec := serveMedia(s, "/videos/video.mp4?token="+s.token, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "video" {
+		t.Errorf("Expected the video with a valid token, got %d %q", rec.Code, rec.Body.String())
+	}
+	rec = serveMedia(s, "/videos/video.mp4", http.Header{mediaTokenHeader: {s.token}})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the token header to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestMediaServerURL(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+	url := s.URL("videos", filepath.Join("clips", "a b.mp4"))
+	want := s.baseURL + "/videos/clips/a%20b.mp4?token=" + s.token
+	if url != want {
+		t.Errorf("Expected %s, got %s", want, url)
+	}
+}
+
+func TestMediaServerRejectsPathsOutsideRoot(t *testing.T) {
+	s, root, secret := newTestMediaServer(t)
+	os.Symlink(filepath.Dir(secret), filepath.Join(root, "escape"))
+
+	for _, target := range []string{
+		"/videos/../secret.txt",
+		"/videos/..%2Fsecret.txt",
+		"/videos/recordings/..%2F..%2Fsecret.txt",
+		"/videos/" + strings.TrimPrefix(filepath.ToSlash(secret), "/"),
+		"/videos//" + strings.TrimPrefix(filepath.ToSlash(secret), "/"),
+		"/videos/escape/secret.txt",
+	} {
+		rec := serveMedia(s, target+"?token="+s.token, nil)
+		if rec.Code == http.StatusOK || strings.Contains(rec.Body.String(), "secret") {
+			t.Errorf("Expected %s to be refused, got %d %q", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMediaServerWithoutRoot(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+	s.SetRoot("")
+	if rec := serveMedia(s, "/videos/video.mp4?token="+s.token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a project root, got %d", rec.Code)
+	}
+	if rec := serveMedia(s, "/audio/take?token="+s.token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for audio without a project root, got %d", rec.Code)
+	}
+}
+
+func TestMediaServerAudio(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+
+	rec := serveMedia(s, "/audio/take?token="+s.token, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "take" {
+		t.Errorf("Expected the recording, got %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "audio/wav" {
+		t.Errorf("Expected audio/wav, got %s", ct)
+	}
+	if rec := serveMedia(s, "/audio/missing?token="+s.token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown recording, got %d", rec.Code)
+	}
+	rec = serveMedia(s, "/audio/secret?token="+s.token, nil)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a recording outside the root, got %d", rec.Code)
+	}
+
+	s.SetRecordings(nil)
+	if rec := serveMedia(s, "/audio/take?token="+s.token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 once the recording is gone, got %d", rec.Code)
+	}
+}
+
+func TestMediaServerRange(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+
+	for target, want := range map[string]string{"/videos/video.mp4": "bytes 1-2/5", "/audio/take": "bytes 1-2/4"} {
+		rec := serveMedia(s, target+"?token="+s.token, http.Header{"Range": {"bytes=1-2"}})
+		if rec.Code != http.StatusPartialContent {
+			t.Fatalf("Expected 206 for %s, got %d", target, rec.Code)
+		}
+		if cr := rec.Header().Get("Content-Range"); cr != want {
+			t.Errorf("Expected Content-Range %s for %s, got %s", want, target, cr)
+		}
+		if rec.Body.Len() != 2 {
+			t.Errorf("Expected 2 bytes for %s, got %q", target, rec.Body.String())
+		}
+	}
+	rec := serveMedia(s, "/videos/video.mp4?token="+s.token, http.Header{"Range": {"bytes=2-"}})
+	if cr := rec.Header().Get("Content-Range"); cr != "bytes 2-4/5" || rec.Body.String() != "deo" {
+		t.Errorf("Expected bytes 2-4/5 \"deo\", got %s %q", cr, rec.Body.String())
+	}
+	rec = serveMedia(s, "/videos/video.mp4?token="+s.token, http.Header{"Range": {"bytes=10-20"}})
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Expected 416 for a range past the end, got %d", rec.Code)
+	}
+}
+
+func TestMediaServerETag(t *testing.T) {
+	s, _, _ := newTestMediaServer(t)
+
+	for _, target := range []string{"/videos/video.mp4", "/audio/take"} {
+		rec := serveMedia(s, target+"?token="+s.token, nil)
+		etag := rec.Header().Get("ETag")
+		if etag == "" {
+			t.Fatalf("Expected an ETag for %s", target)
+		}
+		rec = serveMedia(s, target+"?token="+s.token, http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("Expected 304 for %s with a matching ETag, got %d %q", target, rec.Code, rec.Body.String())
+		}
+		rec = serveMedia(s, target+"?token="+s.token, http.Header{"If-None-Match": {`"stale"`}})
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 for %s with a stale ETag, got %d", target, rec.Code)
+		}
+	}
+}
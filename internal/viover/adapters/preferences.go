@@ -0,0 +1,50 @@
+package adapters
+
+import (
+	"log"
+
+	"github.com/edlingao/viover/internal/viover/core"
+)
+
+func (a *App) GetPreferences() core.Preferences {
+	if a.store == nil {
+		return core.DefaultPreferences()
+	}
+	prefs, err := a.store.GetPreferences()
+	if err != nil {
+		log.Println("Error loading preferences:", err)
+	}
+	return prefs
+}
+
+func (a *App) UpdatePreferences(prefs core.Preferences) error {
+	if a.store == nil {
+		return nil
+	}
+	if err := a.store.UpdatePreferences(prefs); err != nil {
+		return err
+	}
+	a.applyPreferences(prefs)
+	return nil
+}
+
+// applyPreferences restores the microphone and input gain. Devices are
+// matched by name because their IDs change between launches.
+func (a *App) applyPreferences(prefs core.Preferences) {
+	a.Microphone.SetInputGain(prefs.InputGainDB)
+	if prefs.MicrophoneName != "" {
+		a.Microphone.SelectDeviceByName(prefs.MicrophoneName)
+	}
+}
+
+// updatePreferences changes and saves some preferences, keeping the rest.
+func (a *App) updatePreferences(update func(prefs *core.Preferences)) {
+	if a.store == nil {
+		return
+	}
+	prefs := a.GetPreferences()
+	update(&prefs)
+	if err := a.store.UpdatePreferences(prefs); err != nil {
+		log.Println("Error saving preferences:", err)
+	}
+}
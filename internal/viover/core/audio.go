@@ -2,41 +2,49 @@ package core
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 
-	"github.com/go-audio/audio"
 	"github.com/go-audio/wav"
-	"github.com/mewkiz/flac"
-	"github.com/mewkiz/flac/frame"
-	"github.com/mewkiz/flac/meta"
 )
 
 func findFFmpeg() string {
+	return findBinary("ffmpeg")
+}
+
+func findFFprobe() string {
+	return findBinary("ffprobe")
+}
+
+// findBinary looks for a bundled ffmpeg-suite tool next to the executable
+// and falls back to the one on PATH.
+func findBinary(name string) string {
 	exe, err := os.Executable()
 	if err != nil {
-		return "ffmpeg"
+		return name
 	}
 	exeDir := filepath.Dir(exe)
 
 	var candidates []string
 	if runtime.GOOS == "darwin" {
 		candidates = []string{
-			filepath.Join(exeDir, "..", "Resources", "ffmpeg"),
-			filepath.Join(exeDir, "ffmpeg"),
-			filepath.Join(exeDir, "..", "..", "..", "bin", "ffmpeg"),
+			filepath.Join(exeDir, "..", "Resources", name),
+			filepath.Join(exeDir, name),
+			filepath.Join(exeDir, "..", "..", "..", "bin", name),
 		}
 	} else if runtime.GOOS == "windows" {
 		candidates = []string{
-			filepath.Join(exeDir, "ffmpeg.exe"),
-			filepath.Join(exeDir, "bin", "ffmpeg.exe"),
+			filepath.Join(exeDir, name+".exe"),
+			filepath.Join(exeDir, "bin", name+".exe"),
 		}
 	} else {
 		candidates = []string{
-			filepath.Join(exeDir, "ffmpeg"),
-			filepath.Join(exeDir, "bin", "ffmpeg"),
+			filepath.Join(exeDir, name),
+			filepath.Join(exeDir, "bin", name),
 		}
 	}
 
@@ -45,140 +53,95 @@ func findFFmpeg() string {
 			return path
 		}
 	}
-	return "ffmpeg"
+	return name
 }
 
-func ExportAudio(src, dst string, format string, volume float64) error {
-	switch format {
-	case "wav":
-		return exportToWAV(src, dst, volume)
-	case "mp3":
-		return exportToMP3(src, dst, volume)
-	case "flac":
-		return exportToFLAC(src, dst, volume)
-	default:
-		return fmt.Errorf("unsupported format: %s", format)
+// DecodeToWAV streams src decoded to 16-bit PCM WAV into w using ffmpeg, for
+// sources the webview cannot play directly.
+func DecodeToWAV(src string, w io.Writer) error {
+	ffmpegPath := findFFmpeg()
+	cmd := exec.Command(ffmpegPath, "-v", "error", "-i", src, "-f", "wav", "-acodec", "pcm_s16le", "-")
+	cmd.Stdout = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg error: %w (ffmpeg path: %s)", err, ffmpegPath)
 	}
+	return nil
 }
 
-func readAndScaleWAV(src string, volume float64) (*audio.IntBuffer, int, error) {
-	srcFile, err := os.Open(src)
+// ExportAudio writes src to dst in the given container format, scaled by
+// volume and converted to target. A zero target keeps the source's format.
+func ExportAudio(src, dst string, format string, volume float64, target AudioFormat) error {
+	pcm, err := readWAVFile(src)
 	if err != nil {
-		return nil, 0, err
-	}
-	defer srcFile.Close()
-
-	decoder := wav.NewDecoder(srcFile)
-	if !decoder.IsValidFile() {
-		return nil, 0, fmt.Errorf("invalid WAV file: %s", src)
+		return err
 	}
-
-	buf, err := decoder.FullPCMBuffer()
-	if err != nil {
-		return nil, 0, err
+	if target == (AudioFormat{}) {
+		target = AudioFormat{SampleRate: pcm.SampleRate, BitDepth: pcm.BitDepth, Channels: pcm.Channels}
 	}
-
-	for i, sample := range buf.Data {
-		scaled := float64(sample) * volume
-		if scaled > 32767 {
-			scaled = 32767
-		} else if scaled < -32768 {
-			scaled = -32768
-		}
-		buf.Data[i] = int(scaled)
+	samples := convertPCM(pcm, target.SampleRate, target.Channels)
+	for i, sample := range samples {
+		samples[i] = sample * volume
 	}
-
-	return buf, int(decoder.BitDepth), nil
-}
-
-func exportToWAV(src, dst string, volume float64) error {
-	buf, bitDepth, err := readAndScaleWAV(src, volume)
+	frames := int64(len(samples) / target.Channels)
+	enc, err := newPCMEncoder(dst, format, target.SampleRate, target.BitDepth, target.Channels, frames)
 	if err != nil {
 		return err
 	}
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
+	if err := enc.Write(samples); err != nil {
+		enc.Close()
 		return err
 	}
-	defer dstFile.Close()
-
-	enc := wav.NewEncoder(dstFile, buf.Format.SampleRate, bitDepth, buf.Format.NumChannels, 1)
-	defer enc.Close()
-
-	return enc.Write(buf)
+	return enc.Close()
 }
 
-func exportToMP3(src, dst string, volume float64) error {
-	tmpWav := dst + ".tmp.wav"
-	if err := exportToWAV(src, tmpWav, volume); err != nil {
-		return err
-	}
-	defer os.Remove(tmpWav)
-
-	ffmpegPath := findFFmpeg()
-	cmd := exec.Command(ffmpegPath, "-y", "-i", tmpWav, "-codec:a", "libmp3lame", "-q:a", "2", dst)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg error: %w (ffmpeg path: %s)", err, ffmpegPath)
-	}
-	return nil
+// pcmData holds interleaved samples normalized to [-1, 1].
+type pcmData struct {
+	Samples    []float64
+	SampleRate int
+	BitDepth   int
+	Channels   int
 }
 
-func exportToFLAC(src, dst string, volume float64) error {
-	buf, bitsPerSample, err := readAndScaleWAV(src, volume)
-	if err != nil {
-		return err
-	}
+func (d *pcmData) Frames() int64 {
+	return int64(len(d.Samples) / d.Channels)
+}
 
-	dstFile, err := os.Create(dst)
+func readWAVFile(src string) (*pcmData, error) {
+	srcFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer dstFile.Close()
+	defer srcFile.Close()
 
-	info := &meta.StreamInfo{
-		SampleRate:    uint32(buf.Format.SampleRate),
-		NChannels:     uint8(buf.Format.NumChannels),
-		BitsPerSample: uint8(bitsPerSample),
-		NSamples:      uint64(len(buf.Data)),
+	decoder := wav.NewDecoder(srcFile)
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file: %s", src)
 	}
 
-	enc, err := flac.NewEncoder(dstFile, info)
+	buf, err := decoder.FullPCMBuffer()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer enc.Close()
-
-	const samplesPerBlock = 4096
-	for i := 0; i < len(buf.Data); i += samplesPerBlock {
-		end := i + samplesPerBlock
-		if end > len(buf.Data) {
-			end = len(buf.Data)
-		}
 
-		blockSamples := buf.Data[i:end]
-		subframes := make([]*frame.Subframe, buf.Format.NumChannels)
-		for ch := 0; ch < buf.Format.NumChannels; ch++ {
-			samples := make([]int32, len(blockSamples))
-			for j, s := range blockSamples {
-				samples[j] = int32(s)
-			}
-			subframes[ch] = &frame.Subframe{
-				Samples: samples,
-			}
-		}
-
-		f := &frame.Frame{
-			Subframes: subframes,
-		}
-		if err := enc.WriteFrame(f); err != nil {
-			return err
+	bitDepth := int(decoder.BitDepth)
+	isFloat := decoder.WavAudioFormat == wavFormatFloat
+	fullScale := float64(int64(1) << (bitDepth - 1))
+	samples := make([]float64, len(buf.Data))
+	for i, sample := range buf.Data {
+		if isFloat {
+			samples[i] = float64(math.Float32frombits(uint32(sample)))
+		} else {
+			samples[i] = float64(sample) / fullScale
 		}
 	}
-
-	return nil
+	return &pcmData{
+		Samples:    samples,
+		SampleRate: buf.Format.SampleRate,
+		BitDepth:   bitDepth,
+		Channels:   buf.Format.NumChannels,
+	}, nil
 }
 
 func ApplyVolumeToWAV(src, dst string, volume float64) error {
-	return exportToWAV(src, dst, volume)
+	return ExportAudio(src, dst, "wav", volume, AudioFormat{})
 }
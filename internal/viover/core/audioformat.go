@@ -0,0 +1,90 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// AudioFormat is the format a project records and exports in. A bit depth
+// of 32 means 32-bit float; 16 and 24 are integer PCM.
+type AudioFormat struct {
+	SampleRate int `json:"sample_rate"`
+	BitDepth   int `json:"bit_depth"`
+	Channels   int `json:"channels"`
+}
+
+var (
+	SupportedSampleRates = []int{44100, 48000, 96000}
+	SupportedBitDepths   = []int{16, 24, 32}
+)
+
+func DefaultAudioFormat() AudioFormat {
+	return AudioFormat{SampleRate: 44100, BitDepth: 16, Channels: 1}
+}
+
+func (f AudioFormat) Validate() error {
+	if !slices.Contains(SupportedSampleRates, f.SampleRate) {
+		return fmt.Errorf("unsupported sample rate: %d", f.SampleRate)
+	}
+	if !slices.Contains(SupportedBitDepths, f.BitDepth) {
+		return fmt.Errorf("unsupported bit depth: %d", f.BitDepth)
+	}
+	if f.Channels != 1 && f.Channels != 2 {
+		return fmt.Errorf("unsupported channel count: %d", f.Channels)
+	}
+	return nil
+}
+
+func (f AudioFormat) IsFloat() bool {
+	return f.BitDepth == 32
+}
+
+func (f AudioFormat) BytesPerFrame() int {
+	return f.Channels * f.BitDepth / 8
+}
+
+// decodeSample reads one little-endian sample of the given bit depth from
+// the start of b, normalized to [-1, 1].
+func decodeSample(b []byte, bitDepth int) float64 {
+	switch bitDepth {
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float64(v) / (1 << 23)
+	case 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	return 0
+}
+
+// appendSample encodes sample in the given bit depth and appends it to buf.
+// Integer depths are clipped to full scale; float samples are kept as is.
+func appendSample(buf []byte, sample float64, bitDepth int) []byte {
+	if bitDepth == 32 {
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(sample)))
+	}
+	v := floatToPCM(sample, bitDepth)
+	for b := 0; b < bitDepth/8; b++ {
+		buf = append(buf, byte(v>>(8*b)))
+	}
+	return buf
+}
+
+// convertPCM returns pcm's samples remapped to the given channel count and
+// resampled to sampleRate.
+func convertPCM(pcm *pcmData, sampleRate, channels int) []float64 {
+	samples := pcm.Samples
+	if pcm.Channels != channels {
+		mono := downmixToMono(pcm)
+		samples = make([]float64, len(mono)*channels)
+		for i, s := range mono {
+			for ch := 0; ch < channels; ch++ {
+				samples[i*channels+ch] = s
+			}
+		}
+	}
+	return resampleLinear(samples, channels, pcm.SampleRate, sampleRate)
+}
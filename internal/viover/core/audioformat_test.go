@@ -0,0 +1,78 @@
+package core
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestAudioFormatValidate(t *testing.T) {
+	if err := DefaultAudioFormat().Validate(); err != nil {
+		t.Errorf("Expected default format to be valid, got %v", err)
+	}
+	valid := AudioFormat{SampleRate: 96000, BitDepth: 32, Channels: 2}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected %+v to be valid, got %v", valid, err)
+	}
+	for _, f := range []AudioFormat{
+		{SampleRate: 22050, BitDepth: 16, Channels: 1},
+		{SampleRate: 48000, BitDepth: 8, Channels: 1},
+		{SampleRate: 48000, BitDepth: 24, Channels: 6},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", f)
+		}
+	}
+}
+
+func TestWAVEncoderRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	samples := []float64{0.5, -0.25, 0.125, -1, 0, 0.75}
+	for _, bitDepth := range SupportedBitDepths {
+		path := filepath.Join(tmpDir, "take.wav")
+		enc, err := newWAVEncoder(path, 48000, bitDepth, 2)
+		if err != nil {
+			t.Fatalf("Failed to create %d-bit WAV: %v", bitDepth, err)
+		}
+		enc.Write(samples)
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Failed to close %d-bit WAV: %v", bitDepth, err)
+		}
+		pcm, err := readWAVFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %d-bit WAV: %v", bitDepth, err)
+		}
+		if pcm.SampleRate != 48000 || pcm.Channels != 2 || pcm.BitDepth != bitDepth {
+			t.Errorf("Expected 48000/%d/2, got %d/%d/%d", bitDepth, pcm.SampleRate, pcm.BitDepth, pcm.Channels)
+		}
+		if len(pcm.Samples) != len(samples) {
+			t.Fatalf("Expected %d samples, got %d", len(samples), len(pcm.Samples))
+		}
+		for i, want := range samples {
+			if math.Abs(pcm.Samples[i]-want) > 1e-4 {
+				t.Errorf("%d-bit sample %d: expected %f, got %f", bitDepth, i, want, pcm.Samples[i])
+			}
+		}
+	}
+}
+
+func TestGetWaveformPeaksFloat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "take.wav")
+	enc, err := newWAVEncoder(path, 48000, 32, 2)
+	if err != nil {
+		t.Fatalf("Failed to create WAV: %v", err)
+	}
+	enc.Write([]float64{0.5, -0.1, 0.2, -0.5, 0.1, 0.1, 0, 0})
+	enc.Close()
+
+	peaks, err := GetWaveformPeaks(path, 2)
+	if err != nil {
+		t.Fatalf("Failed to read peaks: %v", err)
+	}
+	if len(peaks) != 2 {
+		t.Fatalf("Expected 2 peaks, got %d", len(peaks))
+	}
+	if peaks[0] != 16383 || peaks[1] != 3276 {
+		t.Errorf("Expected peaks [16383 3276] on a 16-bit scale, got %v", peaks)
+	}
+}
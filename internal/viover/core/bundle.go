@@ -0,0 +1,310 @@
+package core
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BundleExtension     = ".viover"
+	bundleManifestName  = "manifest.json"
+	bundleFormatVersion = 1
+)
+
+type BundleManifest struct {
+	FormatVersion int          `json:"format_version"`
+	ProjectID     string       `json:"project_id"`
+	Title         string       `json:"title"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Files         []BundleFile `json:"files"`
+}
+
+type BundleFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type BundleOptions struct {
+	IncludeVideo bool `json:"includeVideo"`
+}
+
+// bundleEntry is a file to pack, read either from disk or from memory.
+type bundleEntry struct {
+	name   string
+	source string
+	data   []byte
+}
+
+// PackProject writes the project, its recordings and optionally its video
+// into a single zip archive with a manifest of SHA-256 checksums. Media kept
+// outside the project folder is pulled into the bundle.
+func PackProject(p *Project, dst string, opts BundleOptions) error {
+	bundled := p.portable()
+	entries := make([]bundleEntry, 0, len(p.Recordings)+2)
+	used := make(map[string]bool)
+	addMedia := func(source, dir string) string {
+		name := relativeTo(p.Path, source)
+		if filepath.IsAbs(name) {
+			name = path.Join(dir, filepath.Base(source))
+		}
+		for i := 2; used[name]; i++ {
+			ext := path.Ext(name)
+			name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), i, ext)
+		}
+		used[name] = true
+		entries = append(entries, bundleEntry{name: name, source: source})
+		return name
+	}
+
+	for i, r := range p.Recordings {
+		if !fileExists(r.FilePath) {
+			return fmt.Errorf("recording file is missing: %s", r.FilePath)
+		}
+		bundled.Recordings[i].FilePath = addMedia(r.FilePath, "recordings")
+	}
+	if p.Video != nil && opts.IncludeVideo {
+		if !fileExists(p.Video.FilePath) {
+			return fmt.Errorf("video file is missing: %s", p.Video.FilePath)
+		}
+		bundled.Video.FilePath = addMedia(p.Video.FilePath, ".")
+	} else if bundled.Video != nil && filepath.IsAbs(bundled.Video.FilePath) {
+		bundled.Video.FilePath = filepath.Base(bundled.Video.FilePath)
+	}
+
+	bundled.SchemaVersion = ProjectSchemaVersion
+	bundled.Path = ""
+	projectData, err := json.MarshalIndent(bundled, "", "  ")
+	if err != nil {
+		return err
+	}
+	entries = append([]bundleEntry{{name: projectFileName, data: projectData}}, entries...)
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := writeBundle(out, p, entries); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+func writeBundle(out io.Writer, p *Project, entries []bundleEntry) error {
+	zw := zip.NewWriter(out)
+	manifest := BundleManifest{
+		FormatVersion: bundleFormatVersion,
+		ProjectID:     p.ID,
+		Title:         p.Title,
+		CreatedAt:     time.Now(),
+		Files:         make([]BundleFile, 0, len(entries)),
+	}
+	for _, e := range entries {
+		// Audio and video barely compress, so media is stored as is.
+		method := zip.Store
+		if e.data != nil {
+			method = zip.Deflate
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: method, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		var size int64
+		if e.data != nil {
+			n, err := io.MultiWriter(w, h).Write(e.data)
+			if err != nil {
+				return err
+			}
+			size = int64(n)
+		} else {
+			f, err := os.Open(e.source)
+			if err != nil {
+				return err
+			}
+			size, err = io.Copy(io.MultiWriter(w, h), f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		manifest.Files = append(manifest.Files, BundleFile{Path: e.name, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create(bundleManifestName)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(manifestData); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// UnpackProject extracts a bundle into a new folder inside destDir, verifying
+// every checksum, and loads the project. Unless preserveID is set the
+// project gets a fresh ID so it can live alongside the original.
+func UnpackProject(src, destDir string, preserveID bool) (*Project, error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	manifest, err := readManifest(&zr.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion > bundleFormatVersion {
+		return nil, fmt.Errorf("bundle format version %d is not supported", manifest.FormatVersion)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	if _, ok := files[projectFileName]; !ok {
+		return nil, fmt.Errorf("bundle has no %s", projectFileName)
+	}
+
+	projectDir := uniqueDir(filepath.Join(destDir, SanitizeFilename(manifest.Title)))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return nil, err
+	}
+	for _, entry := range manifest.Files {
+		f, ok := files[entry.Path]
+		if !ok {
+			os.RemoveAll(projectDir)
+			return nil, fmt.Errorf("bundle is missing %s", entry.Path)
+		}
+		if err := extractBundleFile(f, projectDir, entry); err != nil {
+			os.RemoveAll(projectDir)
+			return nil, err
+		}
+	}
+	os.MkdirAll(filepath.Join(projectDir, "recordings"), 0755)
+
+	project, err := LoadProject(projectDir)
+	if err != nil {
+		os.RemoveAll(projectDir)
+		return nil, err
+	}
+	if !preserveID {
+		project.ID = uuid.NewString()
+	}
+	if err := project.Save(); err != nil {
+		os.RemoveAll(projectDir)
+		return nil, err
+	}
+	return project, nil
+}
+
+// ReadBundleProject decodes the project.json inside a bundle without
+// extracting it, so the project it holds can be checked before importing.
+func ReadBundleProject(src string) (*Project, error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	f, err := zr.Open(projectFileName)
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no %s", projectFileName)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProject(data, "")
+}
+
+// ReadBundleManifest returns the manifest of a bundle without extracting it.
+func ReadBundleManifest(src string) (*BundleManifest, error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readManifest(&zr.Reader)
+}
+
+func readManifest(zr *zip.Reader) (*BundleManifest, error) {
+	for _, f := range zr.File {
+		if f.Name != bundleManifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		var manifest BundleManifest
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+		}
+		return &manifest, nil
+	}
+	return nil, fmt.Errorf("bundle has no %s", bundleManifestName)
+}
+
+func extractBundleFile(f *zip.File, dir string, entry BundleFile) error {
+	name := filepath.FromSlash(entry.Path)
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("invalid path in bundle: %s", entry.Path)
+	}
+	target := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	// Reading one byte past the manifest size is enough to tell the entry is
+	// too large, without letting a crafted bundle fill the disk first.
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(rc, entry.Size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size > entry.Size {
+		return fmt.Errorf("%s is larger than the manifest says", entry.Path)
+	}
+	if size != entry.Size || hex.EncodeToString(h.Sum(nil)) != entry.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s", entry.Path)
+	}
+	return nil
+}
+
+// uniqueDir returns dir, or dir with a numeric suffix if it already exists.
+func uniqueDir(dir string) string {
+	candidate := dir
+	for i := 2; fileExists(candidate); i++ {
+		candidate = fmt.Sprintf("%s (%d)", dir, i)
+	}
+	return candidate
+}
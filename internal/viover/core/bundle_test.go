@@ -0,0 +1,153 @@
+package core
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newBundleProject(t *testing.T) *Project {
+	dir := filepath.Join(t.TempDir(), "Source")
+	os.MkdirAll(filepath.Join(dir, "recordings"), 0755)
+	p := NewProject("Bundle Test", dir)
+	os.WriteFile(filepath.Join(dir, "clip.mp4"), []byte("video"), 0644)
+	p.SetVideo(&Video{ID: "clip.mp4", FileName: "clip.mp4", FilePath: filepath.Join(dir, "clip.mp4")})
+	c := NewCharacter("Alice", "#ff0000")
+	p.AddCharacter(c)
+	inside := filepath.Join(dir, "recordings", "take.wav")
+	os.WriteFile(inside, []byte("inside"), 0644)
+	p.AddRecording(NewRecording(c.ID, inside, 1, 1))
+	outside := filepath.Join(t.TempDir(), "take.wav")
+	os.WriteFile(outside, []byte("outside"), 0644)
+	p.AddRecording(NewRecording(c.ID, outside, 5, 1))
+	if err := p.Save(); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	return p
+}
+
+func TestPackUnpackProject(t *testing.T) {
+	p := newBundleProject(t)
+	bundle := filepath.Join(t.TempDir(), "test.viover")
+	if err := PackProject(p, bundle, BundleOptions{IncludeVideo: true}); err != nil {
+		t.Fatalf("Failed to pack project: %v", err)
+	}
+	manifest, err := ReadBundleManifest(bundle)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if manifest.ProjectID != p.ID || len(manifest.Files) != 4 {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	bundled, err := ReadBundleProject(bundle)
+	if err != nil {
+		t.Fatalf("Failed to read bundled project: %v", err)
+	}
+	if bundled.ID != p.ID || bundled.Path != "" {
+		t.Errorf("Expected the bundled project to keep its ID and drop its path, got %s %q", bundled.ID, bundled.Path)
+	}
+
+	dest := t.TempDir()
+	copied, err := UnpackProject(bundle, dest, false)
+	if err != nil {
+		t.Fatalf("Failed to unpack project: %v", err)
+	}
+	if copied.ID == p.ID {
+		t.Error("Expected a fresh project ID")
+	}
+	if copied.Path != filepath.Join(dest, "Bundle Test") {
+		t.Errorf("Unexpected project path: %s", copied.Path)
+	}
+	if len(copied.MissingMedia()) != 0 {
+		t.Errorf("Expected all media present, missing %+v", copied.MissingMedia())
+	}
+	data, _ := os.ReadFile(copied.Recordings[1].FilePath)
+	if string(data) != "outside" {
+		t.Errorf("Expected external recording pulled into the bundle, got %q", data)
+	}
+
+	preserved, err := UnpackProject(bundle, dest, true)
+	if err != nil {
+		t.Fatalf("Failed to unpack project: %v", err)
+	}
+	if preserved.ID != p.ID {
+		t.Error("Expected the project ID to be preserved")
+	}
+	if preserved.Path == copied.Path {
+		t.Error("Expected a second unpack to use a new folder")
+	}
+}
+
+func TestPackProjectWithoutVideo(t *testing.T) {
+	p := newBundleProject(t)
+	bundle := filepath.Join(t.TempDir(), "test.viover")
+	if err := PackProject(p, bundle, BundleOptions{}); err != nil {
+		t.Fatalf("Failed to pack project: %v", err)
+	}
+	unpacked, err := UnpackProject(bundle, t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Failed to unpack project: %v", err)
+	}
+	missing := unpacked.MissingMedia()
+	if len(missing) != 1 || missing[0].Kind != MediaVideo {
+		t.Errorf("Expected only the video to be missing, got %+v", missing)
+	}
+}
+
+func TestUnpackProjectRejectsCorruptBundle(t *testing.T) {
+	p := newBundleProject(t)
+	bundle := filepath.Join(t.TempDir(), "test.viover")
+	PackProject(p, bundle, BundleOptions{})
+
+	zr, _ := zip.OpenReader(bundle)
+	tampered := filepath.Join(t.TempDir(), "tampered.viover")
+	out, _ := os.Create(tampered)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		w, _ := zw.Create(f.Name)
+		if f.Name == "recordings/take.wav" {
+			w.Write([]byte("tampered"))
+			continue
+		}
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		w.Write(data)
+	}
+	zw.Close()
+	out.Close()
+	zr.Close()
+
+	dest := t.TempDir()
+	if _, err := UnpackProject(tampered, dest, false); err == nil {
+		t.Error("Expected checksum error")
+	}
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 0 {
+		t.Error("Expected a failed unpack to clean up")
+	}
+}
+
+func TestExtractBundleFileStopsAtManifestSize(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "big.viover")
+	out, _ := os.Create(bundle)
+	zw := zip.NewWriter(out)
+	w, _ := zw.Create("recordings/take.wav")
+	w.Write(make([]byte, 1<<20))
+	zw.Close()
+	out.Close()
+
+	zr, _ := zip.OpenReader(bundle)
+	defer zr.Close()
+	dir := t.TempDir()
+	err := extractBundleFile(zr.File[0], dir, BundleFile{Path: "recordings/take.wav", Size: 10})
+	if err == nil {
+		t.Fatal("Expected an error for an entry larger than the manifest")
+	}
+	info, _ := os.Stat(filepath.Join(dir, "recordings", "take.wav"))
+	if info.Size() > 11 {
+		t.Errorf("Expected extraction to stop after 11 bytes, wrote %d", info.Size())
+	}
+}
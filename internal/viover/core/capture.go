@@ -0,0 +1,30 @@
+package core
+
+// CaptureDevice is an input device as reported by a CaptureBackend. ID is the
+// backend's own identifier and is only meaningful to the backend that
+// produced it.
+type CaptureDevice struct {
+	ID   string
+	Name string
+}
+
+// CaptureConfig is the format a stream delivers. BitDepth follows
+// AudioFormat: 16 and 24 are signed integers, 32 is float.
+type CaptureConfig struct {
+	SampleRate int
+	BitDepth   int
+	Channels   int
+}
+
+// CaptureBackend supplies input devices and delivers captured frames as
+// interleaved little-endian PCM in the requested format.
+type CaptureBackend interface {
+	Devices() ([]CaptureDevice, error)
+	Open(deviceID string, cfg CaptureConfig, onData func(samples []byte)) (CaptureStream, error)
+}
+
+type CaptureStream interface {
+	Start() error
+	Stop() error
+	Close()
+}
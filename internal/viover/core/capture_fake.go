@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-audio/wav"
+)
+
+// PCMSource returns the mono sample a FakeBackend delivers for a given frame.
+type PCMSource func(frame int64) int16
+
+func SineSource(freq float64, amplitude int16, sampleRate int) PCMSource {
+	return func(frame int64) int16 {
+		return int16(float64(amplitude) * math.Sin(2*math.Pi*freq*float64(frame)/float64(sampleRate)))
+	}
+}
+
+// WAVSource plays the first channel of a WAV file, followed by silence.
+func WAVSource(path string) (PCMSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := wav.NewDecoder(file)
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file: %s", path)
+	}
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, err
+	}
+	channels := buf.Format.NumChannels
+	return func(frame int64) int16 {
+		i := int(frame) * channels
+		if i >= len(buf.Data) {
+			return 0
+		}
+		return int16(buf.Data[i])
+	}, nil
+}
+
+// FakeBackend is a deterministic CaptureBackend for tests and development
+// without audio hardware. Each stream delivers ChunkFrames frames from Source
+// every Interval until MaxFrames have been delivered, then signals Exhausted.
+type FakeBackend struct {
+	DeviceList  []CaptureDevice
+	Source      PCMSource
+	ChunkFrames int
+	Interval    time.Duration
+	MaxFrames   int64
+
+	exhausted chan struct{}
+	mu        sync.Mutex
+}
+
+func NewFakeBackend(source PCMSource) *FakeBackend {
+	return &FakeBackend{
+		DeviceList: []CaptureDevice{
+			{ID: "fake-0", Name: "Fake Microphone"},
+		},
+		Source:      source,
+		ChunkFrames: 441,
+		Interval:    10 * time.Millisecond,
+		exhausted:   make(chan struct{}, 16),
+	}
+}
+
+func (b *FakeBackend) Devices() ([]CaptureDevice, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]CaptureDevice(nil), b.DeviceList...), nil
+}
+
+// SetDevices replaces the device list, simulating hot-plugging.
+func (b *FakeBackend) SetDevices(devices []CaptureDevice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.DeviceList = devices
+}
+
+func (b *FakeBackend) Open(deviceID string, cfg CaptureConfig, onData func(samples []byte)) (CaptureStream, error) {
+	if deviceID != "" && !b.hasDevice(deviceID) {
+		return nil, fmt.Errorf("device not found: %s", deviceID)
+	}
+	return &fakeStream{backend: b, cfg: cfg, onData: onData}, nil
+}
+
+// Exhausted receives once for every stream that has delivered MaxFrames.
+func (b *FakeBackend) Exhausted() <-chan struct{} {
+	return b.exhausted
+}
+
+func (b *FakeBackend) hasDevice(id string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, d := range b.DeviceList {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeStream struct {
+	backend *FakeBackend
+	cfg     CaptureConfig
+	onData  func(samples []byte)
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func (s *fakeStream) Start() error {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+func (s *fakeStream) run() {
+	defer s.wg.Done()
+	b := s.backend
+	channels := max(s.cfg.Channels, 1)
+	bitDepth := s.cfg.BitDepth
+	if bitDepth == 0 {
+		bitDepth = 16
+	}
+	chunk := make([]byte, 0, b.ChunkFrames*channels*bitDepth/8)
+	var frame int64
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		frames := int64(b.ChunkFrames)
+		if b.MaxFrames > 0 && frame+frames > b.MaxFrames {
+			frames = b.MaxFrames - frame
+		}
+		out := chunk[:0]
+		for i := int64(0); i < frames; i++ {
+			sample := float64(b.Source(frame+i)) / (1 << 15)
+			for ch := 0; ch < channels; ch++ {
+				out = appendSample(out, sample, bitDepth)
+			}
+		}
+		if frames > 0 {
+			s.onData(out)
+		}
+		frame += frames
+		if b.MaxFrames > 0 && frame >= b.MaxFrames {
+			select {
+			case b.exhausted <- struct{}{}:
+			default:
+			}
+			<-s.stop
+			return
+		}
+		if b.Interval > 0 {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(b.Interval):
+			}
+		}
+	}
+}
+
+func (s *fakeStream) Stop() error {
+	if s.stop != nil {
+		close(s.stop)
+		s.wg.Wait()
+		s.stop = nil
+	}
+	return nil
+}
+
+func (s *fakeStream) Close() {
+	s.Stop()
+}
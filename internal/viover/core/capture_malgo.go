@@ -0,0 +1,112 @@
+package core
+
+import (
+	"encoding/hex"
+
+	"github.com/gen2brain/malgo"
+)
+
+type MalgoBackend struct{}
+
+func NewMalgoBackend() *MalgoBackend {
+	return &MalgoBackend{}
+}
+
+func (b *MalgoBackend) Devices() ([]CaptureDevice, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ctx.Uninit()
+
+	infos, err := ctx.Devices(malgo.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]CaptureDevice, 0, len(infos))
+	for _, info := range infos {
+		full, err := ctx.DeviceInfo(malgo.Capture, info.ID, malgo.Shared)
+		name := info.Name()
+		if err == nil {
+			name = full.Name()
+		}
+		devices = append(devices, CaptureDevice{
+			ID:   info.ID.String(),
+			Name: name,
+		})
+	}
+	return devices, nil
+}
+
+func (b *MalgoBackend) Open(deviceID string, cfg CaptureConfig, onData func(samples []byte)) (CaptureStream, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgoFormat(cfg.BitDepth)
+	deviceConfig.Capture.Channels = uint32(cfg.Channels)
+	deviceConfig.SampleRate = uint32(cfg.SampleRate)
+	if id, ok := decodeMalgoDeviceID(deviceID); ok {
+		deviceConfig.Capture.DeviceID = id.Pointer()
+	}
+
+	callbacks := malgo.DeviceCallbacks{
+		Data: func(_, inputSamples []byte, _ uint32) {
+			onData(inputSamples)
+		},
+	}
+
+	device, err := malgo.InitDevice(ctx.Context, deviceConfig, callbacks)
+	if err != nil {
+		ctx.Uninit()
+		ctx.Free()
+		return nil, err
+	}
+	return &malgoStream{ctx: ctx, device: device}, nil
+}
+
+func malgoFormat(bitDepth int) malgo.FormatType {
+	switch bitDepth {
+	case 24:
+		return malgo.FormatS24
+	case 32:
+		return malgo.FormatF32
+	default:
+		return malgo.FormatS16
+	}
+}
+
+func decodeMalgoDeviceID(deviceID string) (malgo.DeviceID, bool) {
+	var id malgo.DeviceID
+	if deviceID == "" {
+		return id, false
+	}
+	raw, err := hex.DecodeString(deviceID)
+	if err != nil || len(raw) > len(id) {
+		return id, false
+	}
+	copy(id[:], raw)
+	return id, true
+}
+
+type malgoStream struct {
+	ctx    *malgo.AllocatedContext
+	device *malgo.Device
+}
+
+func (s *malgoStream) Start() error {
+	return s.device.Start()
+}
+
+func (s *malgoStream) Stop() error {
+	return s.device.Stop()
+}
+
+func (s *malgoStream) Close() {
+	s.device.Uninit()
+	s.ctx.Uninit()
+	s.ctx.Free()
+}
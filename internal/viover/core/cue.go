@@ -0,0 +1,236 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cue is one line of the script: what a character says between Start and
+// End, in seconds. Speaker is the name the line was imported with. An
+// unplaced cue has not been put on the timeline yet; its Start is zero and
+// its End the estimated length of the line.
+type Cue struct {
+	ID          string  `json:"id"`
+	CharacterID string  `json:"character_id"`
+	Speaker     string  `json:"speaker,omitempty"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Text        string  `json:"text"`
+	Unplaced    bool    `json:"unplaced,omitempty"`
+}
+
+// characterColors matches the palette the editor picks from.
+var characterColors = []string{
+	"#00ffcc",
+	"#00d4ff",
+	"#00ff88",
+	"#ffaa00",
+	"#ff7f7f",
+	"#cc99ff",
+	"#ffcc00",
+	"#ff99cc",
+}
+
+// characterColor picks the colour for the nth character: the palette
+// first, then hues spaced by the golden angle so later ones stay distinct.
+func characterColor(n int) string {
+	if n < len(characterColors) {
+		return characterColors[n]
+	}
+	h := math.Mod(float64(n-len(characterColors))*137.508+15, 360)
+	s, l := 0.75, 0.6
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = c, x
+	case h < 120:
+		r, g = x, c
+	case h < 180:
+		g, b = c, x
+	case h < 240:
+		g, b = x, c
+	case h < 300:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round((r+m)*255)), int(math.Round((g+m)*255)), int(math.Round((b+m)*255)))
+}
+
+func NewCue(speaker, text string, start, end float64) *Cue {
+	return &Cue{
+		ID:      uuid.NewString(),
+		Speaker: speaker,
+		Start:   start,
+		End:     end,
+		Text:    text,
+	}
+}
+
+// NewUnplacedCue creates a cue that is not on the timeline yet, sized from
+// the number of words in the line.
+func NewUnplacedCue(speaker, text string) *Cue {
+	c := NewCue(speaker, text, 0, estimateCueDuration(text))
+	c.Unplaced = true
+	return c
+}
+
+// estimateCueDuration assumes a speaking rate of about 2.5 words a second,
+// with a one second minimum.
+func estimateCueDuration(text string) float64 {
+	d := float64(len(strings.Fields(text))) / 2.5
+	return math.Max(1, math.Round(d*10)/10)
+}
+
+func (p *Project) GetCue(id string) *Cue {
+	for _, c := range p.Cues {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (p *Project) GetCharacterByName(name string) *Character {
+	for _, c := range p.Characters {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (p *Project) GetCuesForCharacter(characterID string) []*Cue {
+	cues := make([]*Cue, 0)
+	for _, c := range p.Cues {
+		if c.CharacterID == characterID {
+			cues = append(cues, c)
+		}
+	}
+	return cues
+}
+
+// sortCues orders placed cues by start time, followed by unplaced ones in
+// script order.
+func (p *Project) sortCues() {
+	sort.SliceStable(p.Cues, func(i, j int) bool {
+		a, b := p.Cues[i], p.Cues[j]
+		if a.Unplaced != b.Unplaced {
+			return b.Unplaced
+		}
+		return a.Start < b.Start
+	})
+}
+
+type importCuesCommand struct {
+	cues       []*Cue
+	replace    bool
+	characters []*Character
+	prev       []*Cue
+	resolved   bool
+}
+
+// NewImportCuesCommand adds cues to the project, mapping each speaker to the
+// character with the same name and creating characters that do not exist
+// yet. With replace the project's existing cues are dropped first.
+func NewImportCuesCommand(cues []*Cue, replace bool) Command {
+	return &importCuesCommand{cues: cues, replace: replace}
+}
+
+func (c *importCuesCommand) Label() string { return "Import cues" }
+
+func (c *importCuesCommand) Do(p *Project) error {
+	if !c.resolved {
+		c.resolveSpeakers(p)
+	}
+	for _, character := range c.characters {
+		p.AddCharacter(character)
+	}
+	c.prev = p.Cues
+	if c.replace {
+		p.Cues = nil
+	}
+	p.Cues = append(append(make([]*Cue, 0, len(p.Cues)+len(c.cues)), p.Cues...), c.cues...)
+	p.sortCues()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *importCuesCommand) Undo(p *Project) error {
+	p.Cues = c.prev
+	for _, character := range c.characters {
+		p.RemoveCharacter(character.ID)
+	}
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// resolveSpeakers assigns every cue a character once, so redoing the import
+// brings back the same characters rather than creating new ones.
+func (c *importCuesCommand) resolveSpeakers(p *Project) {
+	c.resolved = true
+	created := make(map[string]*Character)
+	for _, cue := range c.cues {
+		if cue.CharacterID != "" || cue.Speaker == "" {
+			continue
+		}
+		key := strings.ToLower(cue.Speaker)
+		character := p.GetCharacterByName(cue.Speaker)
+		if character == nil {
+			character = created[key]
+		}
+		if character == nil {
+			character = NewCharacter(cue.Speaker, characterColor(len(p.Characters)+len(c.characters)))
+			created[key] = character
+			c.characters = append(c.characters, character)
+		}
+		cue.CharacterID = character.ID
+	}
+}
+
+type placeCueCommand struct {
+	id    string
+	start float64
+	prev  Cue
+}
+
+// NewPlaceCueCommand puts a cue on the timeline at start, keeping its
+// length. It also moves cues that are already placed.
+func NewPlaceCueCommand(id string, start float64) Command {
+	return &placeCueCommand{id: id, start: math.Max(0, start)}
+}
+
+func (c *placeCueCommand) Label() string { return "Place cue" }
+
+func (c *placeCueCommand) Do(p *Project) error {
+	cue := p.GetCue(c.id)
+	if cue == nil {
+		return fmt.Errorf("cue not found: %s", c.id)
+	}
+	c.prev = *cue
+	cue.End = c.start + (cue.End - cue.Start)
+	cue.Start = c.start
+	cue.Unplaced = false
+	p.sortCues()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *placeCueCommand) Undo(p *Project) error {
+	cue := p.GetCue(c.id)
+	if cue == nil {
+		return fmt.Errorf("cue not found: %s", c.id)
+	}
+	cue.Start, cue.End, cue.Unplaced = c.prev.Start, c.prev.End, c.prev.Unplaced
+	p.sortCues()
+	p.UpdatedAt = time.Now()
+	return nil
+}
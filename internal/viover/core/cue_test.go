@@ -0,0 +1,107 @@
+package core
+
+import "testing"
+
+func TestImportCuesCommand(t *testing.T) {
+	p := NewProject("Test", t.TempDir())
+	alice := NewCharacter("Alice", "#ff0000")
+	p.AddCharacter(alice)
+	h := NewHistory(p, 0)
+
+	cues := []*Cue{
+		NewCue("BOB", "Second line", 5, 6),
+		NewCue("ALICE", "First line", 1, 2),
+		NewCue("bob", "Third line", 8, 9),
+		NewCue("", "Narration", 10, 12),
+	}
+	if err := h.Execute(NewImportCuesCommand(cues, false)); err != nil {
+		t.Fatalf("Failed to import cues: %v", err)
+	}
+	if len(p.Characters) != 2 {
+		t.Fatalf("Expected Bob to be created alongside Alice, got %d characters", len(p.Characters))
+	}
+	bob := p.GetCharacterByName("Bob")
+	if bob == nil || bob.Color == "" {
+		t.Fatalf("Expected a coloured character for Bob, got %+v", bob)
+	}
+	if p.Cues[0].Text != "First line" || p.Cues[0].CharacterID != alice.ID {
+		t.Errorf("Expected cues sorted by start and mapped to Alice, got %+v", p.Cues[0])
+	}
+	if len(p.GetCuesForCharacter(bob.ID)) != 2 {
+		t.Errorf("Expected both of Bob's cues to map to one character, got %d", len(p.GetCuesForCharacter(bob.ID)))
+	}
+	if p.Cues[3].CharacterID != "" {
+		t.Error("Expected a cue without a speaker to stay unassigned")
+	}
+
+	h.Undo()
+	if len(p.Cues) != 0 || len(p.Characters) != 1 {
+		t.Errorf("Expected undo to remove cues and Bob, got %d cues and %d characters", len(p.Cues), len(p.Characters))
+	}
+	h.Redo()
+	if p.GetCharacterByName("Bob") != bob || len(p.Cues) != 4 {
+		t.Error("Expected redo to bring back the same character and cues")
+	}
+
+	h.Execute(NewImportCuesCommand([]*Cue{NewCue("Alice", "Replacement", 0, 1)}, true))
+	if len(p.Cues) != 1 || p.Cues[0].Text != "Replacement" {
+		t.Errorf("Expected replace to drop existing cues, got %d cues", len(p.Cues))
+	}
+}
+
+func TestRemoveCharacterUnassignsCues(t *testing.T) {
+	p := NewProject("Test", t.TempDir())
+	h := NewHistory(p, 0)
+	h.Execute(NewImportCuesCommand([]*Cue{NewCue("Alice", "Hi", 0, 1)}, false))
+	alice := p.GetCharacterByName("Alice")
+
+	h.Execute(NewRemoveCharacterCommand(alice.ID))
+	if len(p.Cues) != 1 || p.Cues[0].CharacterID != "" {
+		t.Errorf("Expected the cue to be kept without a character, got %+v", p.Cues)
+	}
+	h.Undo()
+	if p.Cues[0].CharacterID != alice.ID {
+		t.Error("Expected undo to reassign the cue")
+	}
+}
+
+func TestPlaceCueCommand(t *testing.T) {
+	p := NewProject("Test", t.TempDir())
+	h := NewHistory(p, 0)
+	placed := NewCue("Alice", "Placed", 4, 5)
+	first := NewUnplacedCue("Bob", "First unplaced line")
+	second := NewUnplacedCue("Bob", "Second")
+	h.Execute(NewImportCuesCommand([]*Cue{first, placed, second}, false))
+	if p.Cues[0] != placed || p.Cues[1] != first || p.Cues[2] != second {
+		t.Fatalf("Expected placed cues before unplaced ones in script order, got %+v", p.Cues)
+	}
+
+	if err := h.Execute(NewPlaceCueCommand(first.ID, 2)); err != nil {
+		t.Fatalf("Failed to place cue: %v", err)
+	}
+	if first.Unplaced || first.Start != 2 || first.End != 3.2 {
+		t.Errorf("Expected the cue at 2-3.2s, got %+v", first)
+	}
+	if p.Cues[0] != first {
+		t.Error("Expected the placed cue to sort by its start")
+	}
+
+	h.Undo()
+	if !first.Unplaced || first.Start != 0 || first.End != 1.2 || p.Cues[1] != first {
+		t.Errorf("Expected undo to unplace the cue, got %+v", first)
+	}
+	if err := h.Execute(NewPlaceCueCommand("missing", 1)); err == nil {
+		t.Error("Expected an error for an unknown cue")
+	}
+}
+
+func TestCharacterColorsStayDistinct(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 24; i++ {
+		c := characterColor(i)
+		if seen[c] {
+			t.Errorf("Expected a new colour for character %d, got repeated %s", i, c)
+		}
+		seen[c] = true
+	}
+}
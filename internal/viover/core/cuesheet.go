@@ -0,0 +1,214 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	CueSheetCSV  = "csv"
+	CueSheetHTML = "html"
+	CueSheetPDF  = "pdf"
+)
+
+// CueSheetRow is one line of an ADR cue sheet. In and Out are SMPTE
+// timecodes; ChosenTake is zero when no take has been chosen.
+type CueSheetRow struct {
+	Number     int    `json:"number"`
+	Character  string `json:"character"`
+	In         string `json:"in"`
+	Out        string `json:"out"`
+	Text       string `json:"text"`
+	Takes      int    `json:"takes"`
+	ChosenTake int    `json:"chosen_take"`
+	Notes      string `json:"notes"`
+}
+
+var cueSheetHeader = []string{"Cue", "Character", "In", "Out", "Line", "Takes", "Chosen Take", "Notes"}
+
+func (r CueSheetRow) fields() []string {
+	chosen := ""
+	if r.ChosenTake > 0 {
+		chosen = strconv.Itoa(r.ChosenTake)
+	}
+	return []string{strconv.Itoa(r.Number), r.Character, r.In, r.Out, r.Text, strconv.Itoa(r.Takes), chosen, r.Notes}
+}
+
+// CueSheet lists every recorded line, one row per take slot, alongside
+// script cues that have not been recorded yet, in timeline order. Lines not
+// yet placed on the timeline come last, without timecodes.
+func (p *Project) CueSheet() []CueSheetRow {
+	type entry struct {
+		start, end  float64
+		unplaced    bool
+		characterID string
+		speaker     string
+		text        string
+		takes       int
+		chosen      int
+		notes       string
+	}
+	entries := make([]entry, 0)
+	recorded := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, r := range p.Recordings {
+		if seen[r.SlotID] {
+			continue
+		}
+		seen[r.SlotID] = true
+		takes := p.Takes(r.SlotID)
+		ref := takes[len(takes)-1]
+		e := entry{takes: len(takes), notes: "No take chosen"}
+		for _, take := range takes {
+			if take.Active {
+				ref, e.chosen, e.notes = take, take.Take, ""
+			}
+		}
+		e.start, e.end, e.characterID = ref.Timecode, ref.Timecode+ref.Duration, ref.CharacterID
+		if cue := p.cueForRecording(ref); cue != nil {
+			e.text = cue.Text
+			recorded[cue.ID] = true
+		}
+		entries = append(entries, e)
+	}
+	for _, c := range p.Cues {
+		if recorded[c.ID] {
+			continue
+		}
+		e := entry{
+			start:       c.Start,
+			end:         c.End,
+			unplaced:    c.Unplaced,
+			characterID: c.CharacterID,
+			speaker:     c.Speaker,
+			text:        c.Text,
+			notes:       "Not recorded",
+		}
+		if c.Unplaced {
+			e.notes = "Not placed"
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].unplaced != entries[j].unplaced {
+			return entries[j].unplaced
+		}
+		return entries[i].start < entries[j].start
+	})
+
+	rows := make([]CueSheetRow, len(entries))
+	for i, e := range entries {
+		character := e.speaker
+		if c := p.GetCharacter(e.characterID); c != nil {
+			character = c.Name
+		}
+		rows[i] = CueSheetRow{
+			Number:     i + 1,
+			Character:  character,
+			Text:       e.text,
+			Takes:      e.takes,
+			ChosenTake: e.chosen,
+			Notes:      e.notes,
+		}
+		if !e.unplaced {
+			rows[i].In = p.Timecode(e.start).String()
+			rows[i].Out = p.Timecode(e.end).String()
+		}
+	}
+	return rows
+}
+
+// ExportCueSheet writes the project's cue sheet to dst as CSV, HTML or PDF.
+func (p *Project) ExportCueSheet(dst, format string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteCueSheet(f, format); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+func (p *Project) WriteCueSheet(w io.Writer, format string) error {
+	rows := p.CueSheet()
+	switch format {
+	case CueSheetCSV:
+		return writeCueSheetCSV(w, rows)
+	case CueSheetHTML:
+		return writeCueSheetHTML(w, p, rows)
+	case CueSheetPDF:
+		return writeCueSheetPDF(w, p, rows)
+	default:
+		return fmt.Errorf("unsupported cue sheet format: %s", format)
+	}
+}
+
+func writeCueSheetCSV(w io.Writer, rows []CueSheetRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write(cueSheetHeader)
+	for _, r := range rows {
+		cw.Write(r.fields())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cueSheetSubtitle is the line under the title on printed cue sheets.
+func cueSheetSubtitle(p *Project) string {
+	s := "Printed " + time.Now().Format("2006-01-02 15:04")
+	if p.Video != nil && p.Video.FileName != "" {
+		s = p.Video.FileName + " - " + s
+	}
+	return s
+}
+
+var cueSheetTemplate = template.Must(template.New("cuesheet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - ADR Cue Sheet</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 10pt; margin: 2em; color: #111; }
+h1 { font-size: 16pt; margin: 0; }
+p { margin: 0.3em 0 1.2em; color: #555; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.num, td.tc { white-space: nowrap; font-variant-numeric: tabular-nums; }
+td.line { white-space: pre-line; width: 40%; }
+thead { display: table-header-group; }
+tr { page-break-inside: avoid; }
+@page { size: landscape; margin: 1.5cm; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}} - ADR Cue Sheet</h1>
+<p>{{.Subtitle}}</p>
+<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><td class="num">{{.Number}}</td><td>{{.Character}}</td><td class="tc">{{.In}}</td><td class="tc">{{.Out}}</td><td class="line">{{.Text}}</td><td class="num">{{.Takes}}</td><td class="num">{{if .ChosenTake}}{{.ChosenTake}}{{end}}</td><td>{{.Notes}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+func writeCueSheetHTML(w io.Writer, p *Project, rows []CueSheetRow) error {
+	return cueSheetTemplate.Execute(w, map[string]any{
+		"Title":    p.Title,
+		"Subtitle": cueSheetSubtitle(p),
+		"Header":   cueSheetHeader,
+		"Rows":     rows,
+	})
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func cueSheetProject(t *testing.T) *Project {
+	t.Helper()
+	p := NewProject("Dub", t.TempDir())
+	alice := NewCharacter("Alice", "#ff8000")
+	bob := NewCharacter("Bob", "#00d4ff")
+	p.AddCharacter(alice)
+	p.AddCharacter(bob)
+	p.Cues = []*Cue{
+		{ID: "c1", CharacterID: alice.ID, Start: 1, End: 3, Text: "Hello (there)"},
+		{ID: "c2", CharacterID: alice.ID, Start: 3, End: 4, Text: "Other line"},
+		{ID: "c3", Speaker: "Narrator", Start: 10, End: 12, Text: "Meanwhile…"},
+	}
+	p.AddRecording(NewRecording(alice.ID, "/a1.wav", 1.2, 2.0))
+	p.AddRecording(NewRecording(alice.ID, "/a2.wav", 1.2, 2.0))
+	unchosen := NewRecording(bob.ID, "/b.wav", 65.5, 1.5)
+	p.AddRecording(unchosen)
+	unchosen.Active = false
+	return p
+}
+
+func TestProjectCueSheet(t *testing.T) {
+	rows := cueSheetProject(t).CueSheet()
+	want := []CueSheetRow{
+		{Number: 1, Character: "Alice", In: "00:00:01:06", Out: "00:00:03:06", Text: "Hello (there)", Takes: 2, ChosenTake: 2},
+		{Number: 2, Character: "Alice", In: "00:00:03:00", Out: "00:00:04:00", Text: "Other line", Notes: "Not recorded"},
+		{Number: 3, Character: "Narrator", In: "00:00:10:00", Out: "00:00:12:00", Text: "Meanwhile…", Notes: "Not recorded"},
+		{Number: 4, Character: "Bob", In: "00:01:05:15", Out: "00:01:07:00", Takes: 1, Notes: "No take chosen"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d", len(want), len(rows))
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("Expected row %d to be %+v, got %+v", i+1, want[i], rows[i])
+		}
+	}
+}
+
+func TestWriteCueSheetCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := cueSheetProject(t).WriteCueSheet(&buf, CueSheetCSV); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV back: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	if got := strings.Join(records[0], "|"); got != "Cue|Character|In|Out|Line|Takes|Chosen Take|Notes" {
+		t.Errorf("Unexpected header: %s", got)
+	}
+	if got := strings.Join(records[1], "|"); got != "1|Alice|00:00:01:06|00:00:03:06|Hello (there)|2|2|" {
+		t.Errorf("Unexpected first row: %s", got)
+	}
+	if got := records[4][6]; got != "" {
+		t.Errorf("Expected no chosen take, got %q", got)
+	}
+}
+
+func TestWriteCueSheetHTML(t *testing.T) {
+	p := cueSheetProject(t)
+	p.Title = "<Dub>"
+	var buf bytes.Buffer
+	if err := p.WriteCueSheet(&buf, CueSheetHTML); err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "&lt;Dub&gt; - ADR Cue Sheet") {
+		t.Errorf("Expected escaped title in HTML")
+	}
+	if strings.Count(out, "<tr>") != 5 {
+		t.Errorf("Expected 5 table rows, got %d", strings.Count(out, "<tr>"))
+	}
+}
+
+func TestWriteCueSheetPDF(t *testing.T) {
+	p := cueSheetProject(t)
+	for i := 0; i < 80; i++ {
+		p.Cues = append(p.Cues, NewCue("Crowd", strings.Repeat("walla ", 60), 100+float64(i), 101+float64(i)))
+	}
+	var buf bytes.Buffer
+	if err := p.WriteCueSheet(&buf, CueSheetPDF); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Errorf("Expected a PDF header and trailer")
+	}
+	if !strings.Contains(out, `(Hello \(there\))`) {
+		t.Errorf("Expected escaped line text in PDF")
+	}
+	if !strings.Contains(out, "(Meanwhile\x85)") {
+		t.Errorf("Expected ellipsis encoded as WinAnsi")
+	}
+	pages := strings.Count(out, "/Type /Page ")
+	if pages < 2 {
+		t.Fatalf("Expected the sheet to span several pages, got %d", pages)
+	}
+	if strings.Count(out, "(Chosen)") != pages {
+		t.Errorf("Expected the header row on each of %d pages", pages)
+	}
+	if !strings.Contains(out, "(Page 1 of ") {
+		t.Errorf("Expected page numbers in footer")
+	}
+}
+
+func TestWriteCueSheetPDFSplitsTallRows(t *testing.T) {
+	p := NewProject("Dub", t.TempDir())
+	p.Cues = []*Cue{NewCue("Narrator", strings.Repeat("monologue ", 2000)+"END", 1, 2)}
+	var buf bytes.Buffer
+	if err := p.WriteCueSheet(&buf, CueSheetPDF); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	out := buf.String()
+	if pages := strings.Count(out, "/Type /Page "); pages < 3 {
+		t.Errorf("Expected the row to continue over several pages, got %d", pages)
+	}
+	if !strings.Contains(out, "END)") {
+		t.Errorf("Expected the end of the line to be printed")
+	}
+	for _, m := range regexp.MustCompile(`(-?[\d.]+) Td`).FindAllStringSubmatch(out, -1) {
+		if y, _ := strconv.ParseFloat(m[1], 64); y < pdfMargin {
+			t.Fatalf("Expected text to stay inside the page, got y %.2f", y)
+		}
+	}
+}
+
+func TestWriteCueSheetPDFRejectsUnsupportedText(t *testing.T) {
+	p := cueSheetProject(t)
+	p.Cues = append(p.Cues, NewCue("Ivan", "Привет", 20, 21))
+	var buf bytes.Buffer
+	if err := p.WriteCueSheet(&buf, CueSheetPDF); !errors.Is(err, ErrPDFText) {
+		t.Errorf("Expected ErrPDFText, got %v", err)
+	}
+	if err := p.WriteCueSheet(&buf, CueSheetHTML); err != nil {
+		t.Errorf("Expected the HTML cue sheet to print any script, got %v", err)
+	}
+}
+
+func TestWriteCueSheetUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewProject("Dub", t.TempDir()).WriteCueSheet(&buf, "docx"); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText([]byte("a verylongwordthatcannotfit here"), false, 40)
+	for _, line := range lines {
+		if w := textWidth(line, false, pdfFontSize); w > 40 {
+			t.Errorf("Expected line %q to fit in 40pt, got %.1f", line, w)
+		}
+	}
+	if got := string(bytes.Join(lines, nil)); strings.ReplaceAll(got, " ", "") != "averylongwordthatcannotfithere" {
+		t.Errorf("Expected wrapping to keep every character, got %q", got)
+	}
+}
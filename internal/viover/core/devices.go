@@ -0,0 +1,102 @@
+package core
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultDeviceWatchInterval is how often WatchDevices polls the backend.
+const DefaultDeviceWatchInterval = 2 * time.Second
+
+var deviceNamespace = uuid.MustParse("6f1c2a9e-3b7d-4c55-9a8e-2d4f0b6c7e31")
+
+// DevicesChangedEvent is emitted when an input device is plugged in or
+// removed.
+type DevicesChangedEvent struct {
+	Devices []DeviceInfo `json:"devices"`
+}
+
+func (DevicesChangedEvent) EventName() string { return "devices-changed" }
+
+// DeviceFallbackEvent is emitted when the selected device disappears and
+// another one is selected in its place. Interrupted is set when a take was
+// stopped because of it.
+type DeviceFallbackEvent struct {
+	Lost        DeviceInfo `json:"lost"`
+	Selected    DeviceInfo `json:"selected"`
+	Interrupted bool       `json:"interrupted"`
+}
+
+func (DeviceFallbackEvent) EventName() string { return "device-fallback" }
+
+// stableDeviceID derives a device's public ID from the backend ID, or from
+// its name when the backend has none, so it survives relisting.
+func stableDeviceID(info CaptureDevice) string {
+	key := "name:" + info.Name
+	if info.ID != "" {
+		key = "id:" + info.ID
+	}
+	return uuid.NewSHA1(deviceNamespace, []byte(key)).String()
+}
+
+// WatchDevices polls the backend for device changes until the returned stop
+// function is called.
+func (m *Microphone) WatchDevices(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.RefreshDevices()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// RefreshDevices relists the backend's devices, emitting DevicesChangedEvent
+// if the set changed. If the selected device is gone, or none was selected,
+// the first remaining device is selected; losing the selected device also
+// stops any take in progress.
+func (m *Microphone) RefreshDevices() {
+	devices, err := m.listDevices()
+	if err != nil {
+		log.Println("Error listing devices:", err)
+		return
+	}
+
+	m.devMu.Lock()
+	changed := !slices.Equal(m.devices, devices)
+	m.devices = devices
+	lost := m.selectedDevice
+	selectedGone := lost.UUID != "" && !slices.ContainsFunc(devices, func(d DeviceInfo) bool {
+		return d.UUID == lost.UUID
+	})
+	if selectedGone || lost.UUID == "" {
+		m.selectedDevice = DeviceInfo{}
+		if len(devices) > 0 {
+			m.selectedDevice = devices[0]
+		}
+	}
+	selected := m.selectedDevice
+	m.devMu.Unlock()
+
+	if changed {
+		m.events.Emit(DevicesChangedEvent{Devices: devices})
+	}
+	if !selectedGone {
+		return
+	}
+	interrupted := m.IsRecording()
+	if interrupted {
+		m.StopRecording()
+	}
+	m.events.Emit(DeviceFallbackEvent{Lost: lost, Selected: selected, Interrupted: interrupted})
+}
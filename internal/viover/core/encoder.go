@@ -0,0 +1,191 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// pcmEncoder writes interleaved samples in the range [-1, 1] to an audio
+// file block by block, so renders never need the whole file in memory.
+type pcmEncoder interface {
+	Write(samples []float64) error
+	Close() error
+}
+
+func newPCMEncoder(dst, format string, sampleRate, bitDepth, channels int, totalFrames int64) (pcmEncoder, error) {
+	switch format {
+	case "wav":
+		return newWAVEncoder(dst, sampleRate, bitDepth, channels)
+	case "flac":
+		// FLAC has no float samples, so 32-bit float renders as 24-bit.
+		return newFLACEncoder(dst, sampleRate, min(bitDepth, 24), channels, totalFrames)
+	case "mp3":
+		tmpWav := dst + ".tmp.wav"
+		enc, err := newWAVEncoder(tmpWav, sampleRate, bitDepth, channels)
+		if err != nil {
+			return nil, err
+		}
+		return &mp3Encoder{wav: enc, tmpWav: tmpWav, dst: dst}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func floatToPCM(sample float64, bitDepth int) int32 {
+	fullScale := float64(int64(1) << (bitDepth - 1))
+	scaled := math.Round(sample * fullScale)
+	if scaled > fullScale-1 {
+		scaled = fullScale - 1
+	} else if scaled < -fullScale {
+		scaled = -fullScale
+	}
+	return int32(scaled)
+}
+
+type wavEncoder struct {
+	w        *WAVWriter
+	bitDepth int
+	buf      []byte
+}
+
+func newWAVEncoder(dst string, sampleRate, bitDepth, channels int) (*wavEncoder, error) {
+	w, err := NewWAVWriter(dst, sampleRate, bitDepth, channels)
+	if err != nil {
+		return nil, err
+	}
+	return &wavEncoder{w: w, bitDepth: bitDepth}, nil
+}
+
+func (e *wavEncoder) Write(samples []float64) error {
+	e.buf = e.buf[:0]
+	for _, s := range samples {
+		e.buf = appendSample(e.buf, s, e.bitDepth)
+	}
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *wavEncoder) Close() error {
+	return e.w.Close()
+}
+
+const flacBlockFrames = 4096
+
+type flacEncoder struct {
+	file     *os.File
+	enc      *flac.Encoder
+	header   frame.Header
+	channels int
+	pending  []float64
+}
+
+func newFLACEncoder(dst string, sampleRate, bitDepth, channels int, totalFrames int64) (*flacEncoder, error) {
+	var layout frame.Channels
+	switch channels {
+	case 1:
+		layout = frame.ChannelsMono
+	case 2:
+		layout = frame.ChannelsLR
+	default:
+		return nil, fmt.Errorf("unsupported channel count for FLAC: %d", channels)
+	}
+	file, err := os.Create(dst)
+	if err != nil {
+		return nil, err
+	}
+	info := &meta.StreamInfo{
+		BlockSizeMin:  16,
+		BlockSizeMax:  flacBlockFrames,
+		SampleRate:    uint32(sampleRate),
+		NChannels:     uint8(channels),
+		BitsPerSample: uint8(bitDepth),
+		NSamples:      uint64(totalFrames),
+	}
+	enc, err := flac.NewEncoder(file, info)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &flacEncoder{
+		file: file,
+		enc:  enc,
+		header: frame.Header{
+			HasFixedBlockSize: true,
+			SampleRate:        uint32(sampleRate),
+			Channels:          layout,
+			BitsPerSample:     uint8(bitDepth),
+		},
+		channels: channels,
+	}, nil
+}
+
+func (e *flacEncoder) Write(samples []float64) error {
+	e.pending = append(e.pending, samples...)
+	blockLen := flacBlockFrames * e.channels
+	for len(e.pending) >= blockLen {
+		if err := e.writeFrame(e.pending[:blockLen]); err != nil {
+			return err
+		}
+		e.pending = e.pending[blockLen:]
+	}
+	return nil
+}
+
+func (e *flacEncoder) writeFrame(samples []float64) error {
+	frames := len(samples) / e.channels
+	subframes := make([]*frame.Subframe, e.channels)
+	for ch := range subframes {
+		data := make([]int32, frames)
+		for i := range data {
+			data[i] = floatToPCM(samples[i*e.channels+ch], int(e.header.BitsPerSample))
+		}
+		subframes[ch] = &frame.Subframe{
+			SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+			Samples:   data,
+			NSamples:  frames,
+		}
+	}
+	header := e.header
+	header.BlockSize = uint16(frames)
+	return e.enc.WriteFrame(&frame.Frame{Header: header, Subframes: subframes})
+}
+
+func (e *flacEncoder) Close() error {
+	if len(e.pending) > 0 {
+		if err := e.writeFrame(e.pending); err != nil {
+			e.file.Close()
+			return err
+		}
+		e.pending = nil
+	}
+	return e.enc.Close()
+}
+
+type mp3Encoder struct {
+	wav    *wavEncoder
+	tmpWav string
+	dst    string
+}
+
+func (e *mp3Encoder) Write(samples []float64) error {
+	return e.wav.Write(samples)
+}
+
+func (e *mp3Encoder) Close() error {
+	defer os.Remove(e.tmpWav)
+	if err := e.wav.Close(); err != nil {
+		return err
+	}
+	ffmpegPath := findFFmpeg()
+	cmd := exec.Command(ffmpegPath, "-y", "-i", e.tmpWav, "-codec:a", "libmp3lame", "-q:a", "2", e.dst)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg error: %w (ffmpeg path: %s)", err, ffmpegPath)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package core
+
+import "sync"
+
+// Event is a typed notification published by core services. EventName is the
+// name the frontend subscribes to; the struct itself is the payload.
+type Event interface {
+	EventName() string
+}
+
+type EventSink interface {
+	Emit(event Event)
+}
+
+type RecordingStartedEvent struct {
+	CharacterID string `json:"character_id"`
+	RecordingID string `json:"recording_id"`
+}
+
+func (RecordingStartedEvent) EventName() string { return "recording-started" }
+
+type RecordingStoppedEvent struct {
+	CharacterID string  `json:"character_id"`
+	RecordingID string  `json:"recording_id"`
+	Duration    float64 `json:"duration"`
+}
+
+func (RecordingStoppedEvent) EventName() string { return "recording-stopped" }
+
+// AudioSampleEvent carries downsampled input peaks for the live meter.
+type AudioSampleEvent struct {
+	Peaks []int `json:"peaks"`
+}
+
+func (AudioSampleEvent) EventName() string { return "audio-sample" }
+
+type NopEventSink struct{}
+
+func (NopEventSink) Emit(Event) {}
+
+// EventRecorder keeps every emitted event in memory.
+type EventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewEventRecorder() *EventRecorder {
+	return &EventRecorder{}
+}
+
+func (r *EventRecorder) Emit(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *EventRecorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func (r *EventRecorder) Named(name string) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := make([]Event, 0)
+	for _, e := range r.events {
+		if e.EventName() == name {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
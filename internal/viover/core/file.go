@@ -2,8 +2,9 @@ package core
 
 import (
 	"log"
+	"math"
 	"os"
-
+	"strings"
 )
 
 type VideoFile struct {
@@ -26,19 +27,18 @@ func GetVideoFile(path string) *VideoFile {
 }
 
 func (m *VideoFile) GetDuration() int64 {
-	// Calculate duration logic here
-	fileInfo, err := os.Stat(m.Path)
+	video, err := ProbeVideo(m.Path)
 	if err != nil {
-		log.Println("Error getting file info:", err)
+		log.Println("Error probing video file:", err)
 		return 0
 	}
 
-	m.duration = fileInfo.Size() / (1024 * 1024) // Example: size in MB as duration
+	m.duration = int64(math.Round(video.Duration))
 
 	return m.duration
 }
 
-func (m *VideoFile) GetURL() string {
-	return "http://localhost:8080/videos/" + m.Path
+func SanitizeFilename(name string) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	return replacer.Replace(name)
 }
-
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ProjectOK      = "ok"
+	ProjectMissing = "missing"
+	ProjectMoved   = "moved"
+	ProjectCorrupt = "corrupt"
+)
+
+// rediscoverDepth limits how far below the chosen folder RediscoverProjects
+// looks for project.json files.
+const rediscoverDepth = 4
+
+// readProjectFile decodes project.json without recovering recordings or
+// saving, for inspecting projects that are not open.
+func readProjectFile(path string) (*Project, error) {
+	data, err := os.ReadFile(filepath.Join(path, projectFileName))
+	if err != nil {
+		return nil, err
+	}
+	return decodeProject(data, path)
+}
+
+// projectStatus reports the health of a registered project, returning its
+// project.json contents when they are readable.
+func projectStatus(meta ProjectMeta) (string, *Project) {
+	if _, err := os.Stat(filepath.Join(meta.Path, projectFileName)); err != nil {
+		return ProjectMissing, nil
+	}
+	project, err := readProjectFile(meta.Path)
+	if err != nil {
+		return ProjectCorrupt, nil
+	}
+	if project.ID != meta.ID {
+		return ProjectMoved, nil
+	}
+	return ProjectOK, project
+}
+
+// ProjectStatus reports whether a registered project is ok, missing, moved
+// or corrupt.
+func (s *Store) ProjectStatus(meta ProjectMeta) string {
+	status, _ := projectStatus(meta)
+	return status
+}
+
+// RenameProject retitles a project that is not open. project.json is only
+// rewritten while it still belongs to the project; otherwise just the store
+// is updated.
+func (s *Store) RenameProject(meta ProjectMeta, title string) error {
+	if status, project := projectStatus(meta); status == ProjectOK {
+		project.Title = title
+		project.UpdatedAt = time.Now()
+		if err := project.Save(); err != nil {
+			return err
+		}
+	}
+	return s.UpdateProject(meta.ID, title)
+}
+
+// CheckProjects reads every registered project.json to find each project's
+// health and brings titles and timestamps back in line with it. It is run
+// at startup and on demand, and ListProjects reports what it found. Projects
+// that could not be reconciled are still listed, with the errors returned.
+func (s *Store) CheckProjects() ([]ProjectMeta, error) {
+	projects := s.ListProjects()
+	var errs []error
+	for i := range projects {
+		status, project := projectStatus(projects[i])
+		projects[i].Status = status
+		s.setStatus(projects[i].ID, status)
+		if project != nil {
+			if err := s.reconcile(&projects[i], project); err != nil {
+				errs = append(errs, fmt.Errorf("reconciling %s: %w", projects[i].Title, err))
+			}
+		}
+	}
+	return projects, errors.Join(errs...)
+}
+
+// reconcile copies the title and timestamps from project.json into the
+// store if they have drifted.
+func (s *Store) reconcile(meta *ProjectMeta, p *Project) error {
+	createdAt := p.CreatedAt.Format(time.RFC3339)
+	updatedAt := p.UpdatedAt.Format(time.RFC3339)
+	if meta.Title == p.Title && meta.CreatedAt == createdAt && meta.UpdatedAt == updatedAt {
+		return nil
+	}
+	_, err := s.db.Exec(
+		`UPDATE projects SET title = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		p.Title, createdAt, updatedAt, p.ID,
+	)
+	if err != nil {
+		return err
+	}
+	meta.Title, meta.CreatedAt, meta.UpdatedAt = p.Title, createdAt, updatedAt
+	return nil
+}
+
+// RediscoverProjects scans dir for project folders. Registered projects
+// found at a new location have their path updated; unknown ones are
+// registered. It returns the projects it found.
+func (s *Store) RediscoverProjects(dir string) ([]ProjectMeta, error) {
+	found := make([]ProjectMeta, 0)
+	root := filepath.Clean(dir)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if rel, _ := filepath.Rel(root, path); rel != "." && len(strings.Split(rel, string(filepath.Separator))) > rediscoverDepth {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, projectFileName)); err != nil {
+			return nil
+		}
+		project, err := readProjectFile(path)
+		if err != nil {
+			return filepath.SkipDir
+		}
+		meta, err := s.registerFound(project)
+		if err != nil {
+			return err
+		}
+		if meta != nil {
+			found = append(found, *meta)
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// registerFound adds or repoints the store row for a project found on disk.
+// Projects already registered at a healthy location are left alone.
+func (s *Store) registerFound(p *Project) (*ProjectMeta, error) {
+	existing := s.GetProject(p.ID)
+	if existing == nil {
+		if err := s.AddProject(p); err != nil {
+			return nil, err
+		}
+	} else if existing.Path != p.Path {
+		if status, _ := projectStatus(*existing); status == ProjectOK {
+			return nil, nil
+		}
+		if err := s.UpdateProjectPath(p.ID, p.Path); err != nil {
+			return nil, err
+		}
+	}
+	meta := s.GetProject(p.ID)
+	if err := s.reconcile(meta, p); err != nil {
+		return nil, err
+	}
+	meta.Status = ProjectOK
+	s.setStatus(p.ID, ProjectOK)
+	return meta, nil
+}
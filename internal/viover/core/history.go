@@ -0,0 +1,746 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultHistoryDepth is how many commands a History keeps for undo.
+const DefaultHistoryDepth = 100
+
+// HistoryMergeWindow is how soon after one another two commands that can
+// merge, like the steps of a slider drag, must come to share an undo entry.
+const HistoryMergeWindow = time.Second
+
+const trashDir = "trash"
+
+// Command is a reversible project mutation. Do is called again on redo, so
+// it must capture whatever Undo needs each time it runs.
+type Command interface {
+	Label() string
+	Do(p *Project) error
+	Undo(p *Project) error
+}
+
+// discarder is implemented by commands holding audio in the trash, which is
+// deleted for good once the command leaves the history.
+type discarder interface {
+	Discard(p *Project)
+}
+
+// merger is implemented by commands that can absorb the command executed
+// after them, so a run of small edits undoes as one.
+type merger interface {
+	Merge(next Command) bool
+}
+
+type HistoryState struct {
+	Undo []string `json:"undo"`
+	Redo []string `json:"redo"`
+}
+
+// History applies commands to a project and keeps them for undo and redo.
+type History struct {
+	project *Project
+	depth   int
+	undo    []Command
+	redo    []Command
+}
+
+func NewHistory(p *Project, depth int) *History {
+	if depth <= 0 {
+		depth = DefaultHistoryDepth
+	}
+	return &History{project: p, depth: depth}
+}
+
+func (h *History) Execute(c Command) error {
+	if err := c.Do(h.project); err != nil {
+		return err
+	}
+	if len(h.redo) == 0 && len(h.undo) > 0 {
+		if m, ok := h.undo[len(h.undo)-1].(merger); ok && m.Merge(c) {
+			return nil
+		}
+	}
+	h.discard(h.redo)
+	h.redo = nil
+	h.undo = append(h.undo, c)
+	if extra := len(h.undo) - h.depth; extra > 0 {
+		h.discard(h.undo[:extra])
+		h.undo = append([]Command(nil), h.undo[extra:]...)
+	}
+	return nil
+}
+
+// Undo reverts the last command and returns its label, or "" if there is
+// nothing to undo.
+func (h *History) Undo() (string, error) {
+	if len(h.undo) == 0 {
+		return "", nil
+	}
+	c := h.undo[len(h.undo)-1]
+	if err := c.Undo(h.project); err != nil {
+		return "", err
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, c)
+	return c.Label(), nil
+}
+
+// Redo reapplies the last undone command and returns its label, or "" if
+// there is nothing to redo.
+func (h *History) Redo() (string, error) {
+	if len(h.redo) == 0 {
+		return "", nil
+	}
+	c := h.redo[len(h.redo)-1]
+	if err := c.Do(h.project); err != nil {
+		return "", err
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, c)
+	return c.Label(), nil
+}
+
+// State lists the undo and redo labels, most recent first.
+func (h *History) State() HistoryState {
+	state := HistoryState{Undo: make([]string, 0, len(h.undo)), Redo: make([]string, 0, len(h.redo))}
+	for i := len(h.undo) - 1; i >= 0; i-- {
+		state.Undo = append(state.Undo, h.undo[i].Label())
+	}
+	for i := len(h.redo) - 1; i >= 0; i-- {
+		state.Redo = append(state.Redo, h.redo[i].Label())
+	}
+	return state
+}
+
+// Clear drops all history, deleting any audio still held in the trash.
+func (h *History) Clear() {
+	h.discard(h.undo)
+	h.discard(h.redo)
+	h.undo = nil
+	h.redo = nil
+}
+
+func (h *History) discard(commands []Command) {
+	for _, c := range commands {
+		if d, ok := c.(discarder); ok {
+			d.Discard(h.project)
+		}
+	}
+}
+
+// moveToTrash moves a file into the project's trash folder. Missing files
+// are skipped and return an empty path.
+func (p *Project) moveToTrash(path string) (string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+	dir := filepath.Join(p.Path, trashDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	trashPath := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, trashPath); err != nil {
+		return "", err
+	}
+	return trashPath, nil
+}
+
+func restoreFromTrash(trashPath, path string) error {
+	if trashPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.Rename(trashPath, path)
+}
+
+// PurgeTrash deletes audio in the trash that no recording refers to. It is
+// only safe with no history, since undo may still need what is there.
+func (p *Project) PurgeTrash() error {
+	dir := filepath.Join(p.Path, trashDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(p.Recordings))
+	for _, r := range p.Recordings {
+		referenced[filepath.Base(r.FilePath)] = true
+	}
+	for _, entry := range entries {
+		if !referenced[entry.Name()] {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreTrashedRecordings moves audio back out of the trash for any
+// recording whose file is missing, e.g. after rolling back to a backup.
+func (p *Project) restoreTrashedRecordings() {
+	for _, r := range p.Recordings {
+		if _, err := os.Stat(r.FilePath); !os.IsNotExist(err) {
+			continue
+		}
+		trashPath := filepath.Join(p.Path, trashDir, filepath.Base(r.FilePath))
+		if _, err := os.Stat(trashPath); err == nil {
+			restoreFromTrash(trashPath, r.FilePath)
+		}
+	}
+}
+
+func (p *Project) activeSnapshot() map[string]bool {
+	active := make(map[string]bool, len(p.Recordings))
+	for _, r := range p.Recordings {
+		active[r.ID] = r.Active
+	}
+	return active
+}
+
+func (p *Project) restoreActive(active map[string]bool) {
+	for _, r := range p.Recordings {
+		if a, ok := active[r.ID]; ok {
+			r.Active = a
+		}
+	}
+}
+
+func (p *Project) insertRecording(index int, r *Recording) {
+	index = min(index, len(p.Recordings))
+	p.Recordings = append(p.Recordings[:index], append([]*Recording{r}, p.Recordings[index:]...)...)
+}
+
+type addCharacterCommand struct {
+	character *Character
+}
+
+func NewAddCharacterCommand(c *Character) Command {
+	return &addCharacterCommand{character: c}
+}
+
+func (c *addCharacterCommand) Label() string { return "Add character " + c.character.Name }
+
+func (c *addCharacterCommand) Do(p *Project) error {
+	p.AddCharacter(c.character)
+	return nil
+}
+
+func (c *addCharacterCommand) Undo(p *Project) error {
+	p.RemoveCharacter(c.character.ID)
+	return nil
+}
+
+type updateCharacterCommand struct {
+	id, name, color     string
+	prevName, prevColor string
+}
+
+func NewUpdateCharacterCommand(id, name, color string) Command {
+	return &updateCharacterCommand{id: id, name: name, color: color}
+}
+
+func (c *updateCharacterCommand) Label() string { return "Edit character " + c.name }
+
+func (c *updateCharacterCommand) Do(p *Project) error {
+	character := p.GetCharacter(c.id)
+	if character == nil {
+		return fmt.Errorf("character not found: %s", c.id)
+	}
+	c.prevName, c.prevColor = character.Name, character.Color
+	p.UpdateCharacter(c.id, c.name, c.color)
+	return nil
+}
+
+func (c *updateCharacterCommand) Undo(p *Project) error {
+	p.UpdateCharacter(c.id, c.prevName, c.prevColor)
+	return nil
+}
+
+// removeCharacterCommand removes a character and its recordings, moving
+// their audio to the trash.
+type removeCharacterCommand struct {
+	id         string
+	character  *Character
+	index      int
+	recordings []*Recording
+	cues       []*Cue
+	trashed    map[string]string
+	inTrash    bool
+}
+
+func NewRemoveCharacterCommand(id string) Command {
+	return &removeCharacterCommand{id: id}
+}
+
+func (c *removeCharacterCommand) Label() string {
+	if c.character == nil {
+		return "Delete character"
+	}
+	return "Delete character " + c.character.Name
+}
+
+func (c *removeCharacterCommand) Do(p *Project) error {
+	c.character = nil
+	for i, character := range p.Characters {
+		if character.ID == c.id {
+			c.character, c.index = character, i
+			break
+		}
+	}
+	if c.character == nil {
+		return fmt.Errorf("character not found: %s", c.id)
+	}
+	c.recordings = append([]*Recording(nil), p.Recordings...)
+	c.trashed = make(map[string]string)
+	for _, r := range p.GetRecordingsForCharacter(c.id) {
+		trashPath, err := p.moveToTrash(r.FilePath)
+		if err != nil {
+			c.restoreFiles()
+			return err
+		}
+		c.trashed[r.FilePath] = trashPath
+	}
+	c.inTrash = true
+	c.cues = p.GetCuesForCharacter(c.id)
+	p.RemoveCharacter(c.id)
+	return nil
+}
+
+func (c *removeCharacterCommand) Undo(p *Project) error {
+	if err := c.restoreFiles(); err != nil {
+		return err
+	}
+	index := min(c.index, len(p.Characters))
+	p.Characters = append(p.Characters[:index], append([]*Character{c.character}, p.Characters[index:]...)...)
+	p.Recordings = append([]*Recording(nil), c.recordings...)
+	for _, cue := range c.cues {
+		cue.CharacterID = c.id
+	}
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *removeCharacterCommand) restoreFiles() error {
+	for path, trashPath := range c.trashed {
+		if err := restoreFromTrash(trashPath, path); err != nil {
+			return err
+		}
+		delete(c.trashed, path)
+	}
+	c.inTrash = false
+	return nil
+}
+
+func (c *removeCharacterCommand) Discard(p *Project) {
+	if !c.inTrash {
+		return
+	}
+	for _, trashPath := range c.trashed {
+		if trashPath != "" {
+			os.Remove(trashPath)
+		}
+	}
+}
+
+// addRecordingCommand adds a newly recorded take. Undoing it moves the
+// audio to the trash.
+type addRecordingCommand struct {
+	recording *Recording
+	active    map[string]bool
+	trashPath string
+	inTrash   bool
+}
+
+func NewAddRecordingCommand(r *Recording) Command {
+	return &addRecordingCommand{recording: r}
+}
+
+func (c *addRecordingCommand) Label() string { return "Record take" }
+
+func (c *addRecordingCommand) Do(p *Project) error {
+	if c.inTrash {
+		if err := restoreFromTrash(c.trashPath, c.recording.FilePath); err != nil {
+			return err
+		}
+		c.inTrash = false
+	}
+	c.active = p.activeSnapshot()
+	p.AddRecording(c.recording)
+	return nil
+}
+
+func (c *addRecordingCommand) Undo(p *Project) error {
+	trashPath, err := p.moveToTrash(c.recording.FilePath)
+	if err != nil {
+		return err
+	}
+	c.trashPath, c.inTrash = trashPath, true
+	p.RemoveRecording(c.recording.ID)
+	p.restoreActive(c.active)
+	return nil
+}
+
+func (c *addRecordingCommand) Discard(p *Project) {
+	if c.inTrash && c.trashPath != "" {
+		os.Remove(c.trashPath)
+	}
+}
+
+// removeRecordingCommand deletes a take, moving its audio to the trash.
+type removeRecordingCommand struct {
+	id        string
+	recording *Recording
+	index     int
+	active    map[string]bool
+	trashPath string
+	inTrash   bool
+}
+
+func NewRemoveRecordingCommand(id string) Command {
+	return &removeRecordingCommand{id: id}
+}
+
+func (c *removeRecordingCommand) Label() string { return "Delete recording" }
+
+func (c *removeRecordingCommand) Do(p *Project) error {
+	c.recording = nil
+	for i, r := range p.Recordings {
+		if r.ID == c.id {
+			c.recording, c.index = r, i
+			break
+		}
+	}
+	if c.recording == nil {
+		return fmt.Errorf("recording not found: %s", c.id)
+	}
+	trashPath, err := p.moveToTrash(c.recording.FilePath)
+	if err != nil {
+		return err
+	}
+	c.trashPath, c.inTrash = trashPath, true
+	c.active = p.activeSnapshot()
+	p.RemoveRecording(c.id)
+	return nil
+}
+
+func (c *removeRecordingCommand) Undo(p *Project) error {
+	if err := restoreFromTrash(c.trashPath, c.recording.FilePath); err != nil {
+		return err
+	}
+	c.inTrash = false
+	p.insertRecording(c.index, c.recording)
+	p.restoreActive(c.active)
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *removeRecordingCommand) Discard(p *Project) {
+	if c.inTrash && c.trashPath != "" {
+		os.Remove(c.trashPath)
+	}
+}
+
+type moveRecordingCommand struct {
+	id       string
+	timecode float64
+	prev     float64
+}
+
+func NewMoveRecordingCommand(id string, timecode float64) Command {
+	return &moveRecordingCommand{id: id, timecode: timecode}
+}
+
+func (c *moveRecordingCommand) Label() string { return "Move recording" }
+
+func (c *moveRecordingCommand) Do(p *Project) error {
+	r := p.GetRecording(c.id)
+	if r == nil {
+		return fmt.Errorf("recording not found: %s", c.id)
+	}
+	c.prev = r.Timecode
+	p.UpdateRecordingTimecode(c.id, c.timecode)
+	return nil
+}
+
+func (c *moveRecordingCommand) Undo(p *Project) error {
+	p.UpdateRecordingTimecode(c.id, c.prev)
+	return nil
+}
+
+type recordingVolumeCommand struct {
+	id     string
+	volume float64
+	prev   float64
+	at     time.Time
+}
+
+func NewRecordingVolumeCommand(id string, volume float64) Command {
+	return &recordingVolumeCommand{id: id, volume: volume, at: time.Now()}
+}
+
+func (c *recordingVolumeCommand) Label() string { return "Change volume" }
+
+func (c *recordingVolumeCommand) Do(p *Project) error {
+	r := p.GetRecording(c.id)
+	if r == nil {
+		return fmt.Errorf("recording not found: %s", c.id)
+	}
+	c.prev = r.Volume
+	p.UpdateRecordingVolume(c.id, c.volume)
+	return nil
+}
+
+func (c *recordingVolumeCommand) Undo(p *Project) error {
+	p.UpdateRecordingVolume(c.id, c.prev)
+	return nil
+}
+
+func (c *recordingVolumeCommand) Merge(next Command) bool {
+	n, ok := next.(*recordingVolumeCommand)
+	if !ok || n.id != c.id || n.at.Sub(c.at) > HistoryMergeWindow {
+		return false
+	}
+	c.volume, c.at = n.volume, n.at
+	return true
+}
+
+type recordingGainCommand struct {
+	id     string
+	gainDB float64
+	prev   float64
+	at     time.Time
+}
+
+func NewRecordingGainCommand(id string, gainDB float64) Command {
+	return &recordingGainCommand{id: id, gainDB: gainDB, at: time.Now()}
+}
+
+func (c *recordingGainCommand) Label() string { return "Change gain" }
+
+func (c *recordingGainCommand) Do(p *Project) error {
+	r := p.GetRecording(c.id)
+	if r == nil {
+		return fmt.Errorf("recording not found: %s", c.id)
+	}
+	c.prev = r.GainDB
+	p.UpdateRecordingGain(c.id, c.gainDB)
+	return nil
+}
+
+func (c *recordingGainCommand) Undo(p *Project) error {
+	p.UpdateRecordingGain(c.id, c.prev)
+	return nil
+}
+
+func (c *recordingGainCommand) Merge(next Command) bool {
+	n, ok := next.(*recordingGainCommand)
+	if !ok || n.id != c.id || n.at.Sub(c.at) > HistoryMergeWindow {
+		return false
+	}
+	c.gainDB, c.at = n.gainDB, n.at
+	return true
+}
+
+type setActiveTakeCommand struct {
+	id     string
+	active map[string]bool
+}
+
+func NewSetActiveTakeCommand(id string) Command {
+	return &setActiveTakeCommand{id: id}
+}
+
+func (c *setActiveTakeCommand) Label() string { return "Choose take" }
+
+func (c *setActiveTakeCommand) Do(p *Project) error {
+	if p.GetRecording(c.id) == nil {
+		return fmt.Errorf("recording not found: %s", c.id)
+	}
+	c.active = p.activeSnapshot()
+	p.SetActiveTake(c.id)
+	return nil
+}
+
+func (c *setActiveTakeCommand) Undo(p *Project) error {
+	p.restoreActive(c.active)
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+type setVideoCommand struct {
+	video *Video
+	prev  *Video
+}
+
+func NewSetVideoCommand(v *Video) Command {
+	return &setVideoCommand{video: v}
+}
+
+func (c *setVideoCommand) Label() string { return "Change video" }
+
+func (c *setVideoCommand) Do(p *Project) error {
+	c.prev = p.Video
+	p.SetVideo(c.video)
+	return nil
+}
+
+func (c *setVideoCommand) Undo(p *Project) error {
+	p.SetVideo(c.prev)
+	return nil
+}
+
+type setStartTimecodeCommand struct {
+	timecode string
+	prev     string
+}
+
+func NewSetStartTimecodeCommand(timecode string) Command {
+	return &setStartTimecodeCommand{timecode: timecode}
+}
+
+func (c *setStartTimecodeCommand) Label() string { return "Change start timecode" }
+
+func (c *setStartTimecodeCommand) Do(p *Project) error {
+	prev := p.StartTimecode
+	if err := p.SetStartTimecode(c.timecode); err != nil {
+		return err
+	}
+	c.prev = prev
+	return nil
+}
+
+func (c *setStartTimecodeCommand) Undo(p *Project) error {
+	p.StartTimecode = c.prev
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+type renameProjectCommand struct {
+	title string
+	prev  string
+}
+
+func NewRenameProjectCommand(title string) Command {
+	return &renameProjectCommand{title: title}
+}
+
+func (c *renameProjectCommand) Label() string { return "Rename project" }
+
+func (c *renameProjectCommand) Do(p *Project) error {
+	c.prev = p.Title
+	p.Title = c.title
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *renameProjectCommand) Undo(p *Project) error {
+	p.Title = c.prev
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+type setAudioFormatCommand struct {
+	format AudioFormat
+	prev   AudioFormat
+}
+
+func NewSetAudioFormatCommand(format AudioFormat) Command {
+	return &setAudioFormatCommand{format: format}
+}
+
+func (c *setAudioFormatCommand) Label() string { return "Change audio format" }
+
+func (c *setAudioFormatCommand) Do(p *Project) error {
+	prev := p.AudioFormat
+	if err := p.SetAudioFormat(c.format); err != nil {
+		return err
+	}
+	c.prev = prev
+	return nil
+}
+
+func (c *setAudioFormatCommand) Undo(p *Project) error {
+	p.AudioFormat = c.prev
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+type relinkMediaCommand struct {
+	found  []MissingMedia
+	prev   []string
+	copied []string
+}
+
+// NewRelinkMediaCommand copies media found by FindMissingMedia into the
+// project folder and points the project at the copies, so the media server
+// can serve them and the folder stays self-contained.
+func NewRelinkMediaCommand(found []MissingMedia) Command {
+	return &relinkMediaCommand{found: found}
+}
+
+func (c *relinkMediaCommand) Label() string { return "Relink media" }
+
+func (c *relinkMediaCommand) Do(p *Project) error {
+	c.prev = make([]string, len(c.found))
+	c.copied = make([]string, 0, len(c.found))
+	for i, m := range c.found {
+		dst := p.relinkTarget(m)
+		if dst == "" {
+			continue
+		}
+		if dst != m.FilePath {
+			if _, err := os.Stat(dst); err == nil {
+				c.removeCopies()
+				c.restore(p, i)
+				return fmt.Errorf("cannot relink %s: %s already exists", filepath.Base(m.FilePath), dst)
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				c.removeCopies()
+				c.restore(p, i)
+				return err
+			}
+			if err := CopyFile(m.FilePath, dst); err != nil {
+				c.removeCopies()
+				c.restore(p, i)
+				return err
+			}
+			c.copied = append(c.copied, dst)
+		}
+		c.prev[i] = p.mediaPath(m)
+		p.setMediaPath(m, dst)
+	}
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *relinkMediaCommand) Undo(p *Project) error {
+	c.restore(p, len(c.found))
+	c.removeCopies()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// restore points the first n relinked items back at their old paths.
+func (c *relinkMediaCommand) restore(p *Project, n int) {
+	for i := 0; i < n; i++ {
+		if c.prev[i] != "" {
+			p.setMediaPath(c.found[i], c.prev[i])
+		}
+	}
+}
+
+func (c *relinkMediaCommand) removeCopies() {
+	for _, path := range c.copied {
+		os.Remove(path)
+	}
+	c.copied = nil
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	p := NewProject("Test", t.TempDir())
+	h := NewHistory(p, 0)
+	c := NewCharacter("Alice", "#ff0000")
+	if err := h.Execute(NewAddCharacterCommand(c)); err != nil {
+		t.Fatalf("Failed to add character: %v", err)
+	}
+	if err := h.Execute(NewUpdateCharacterCommand(c.ID, "Bob", "#00ff00")); err != nil {
+		t.Fatalf("Failed to update character: %v", err)
+	}
+
+	if label, _ := h.Undo(); label != "Edit character Bob" {
+		t.Errorf("Expected 'Edit character Bob', got '%s'", label)
+	}
+	if c.Name != "Alice" || c.Color != "#ff0000" {
+		t.Errorf("Expected Alice #ff0000, got %s %s", c.Name, c.Color)
+	}
+	h.Undo()
+	if len(p.Characters) != 0 {
+		t.Errorf("Expected 0 characters, got %d", len(p.Characters))
+	}
+	if label, _ := h.Undo(); label != "" {
+		t.Errorf("Expected nothing to undo, got '%s'", label)
+	}
+
+	h.Redo()
+	h.Redo()
+	if len(p.Characters) != 1 || p.Characters[0].Name != "Bob" {
+		t.Errorf("Expected Bob after redo, got %+v", p.Characters)
+	}
+	state := h.State()
+	if len(state.Undo) != 2 || len(state.Redo) != 0 || state.Undo[0] != "Edit character Bob" {
+		t.Errorf("Unexpected history state: %+v", state)
+	}
+
+	h.Undo()
+	h.Execute(NewRenameProjectCommand("Renamed"))
+	if len(h.State().Redo) != 0 {
+		t.Error("Expected a new command to clear redo")
+	}
+}
+
+func TestHistoryDeleteRecordingUsesTrash(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProject("Test", dir)
+	h := NewHistory(p, 0)
+	path := filepath.Join(dir, "recordings", "take.wav")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("audio"), 0644)
+	first := NewRecording("char-1", filepath.Join(dir, "recordings", "missing.wav"), 5.0, 1.0)
+	second := NewRecording("char-1", path, 5.0, 1.0)
+	h.Execute(NewAddRecordingCommand(first))
+	h.Execute(NewAddRecordingCommand(second))
+
+	if err := h.Execute(NewRemoveRecordingCommand(second.ID)); err != nil {
+		t.Fatalf("Failed to delete recording: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected recording to be moved out of recordings")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trash", "take.wav")); err != nil {
+		t.Errorf("Expected recording in trash: %v", err)
+	}
+	if !first.Active {
+		t.Error("Expected the remaining take to become active")
+	}
+
+	h.Undo()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected recording restored: %v", err)
+	}
+	if len(p.Recordings) != 2 || !second.Active || first.Active {
+		t.Errorf("Expected second take restored as active, got %+v", p.Recordings)
+	}
+
+	h.Undo()
+	if p.GetRecording(second.ID) != nil {
+		t.Error("Expected undo to remove the recorded take")
+	}
+	if !first.Active {
+		t.Error("Expected the first take to be active again")
+	}
+	h.Clear()
+	if _, err := os.Stat(filepath.Join(dir, "trash", "take.wav")); !os.IsNotExist(err) {
+		t.Error("Expected clearing history to empty the trash")
+	}
+}
+
+func TestHistoryDeleteCharacterRestoresRecordings(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProject("Test", dir)
+	h := NewHistory(p, 0)
+	c := NewCharacter("Alice", "#ff0000")
+	h.Execute(NewAddCharacterCommand(c))
+	path := filepath.Join(dir, "alice.wav")
+	os.WriteFile(path, []byte("audio"), 0644)
+	h.Execute(NewAddRecordingCommand(NewRecording(c.ID, path, 1.0, 1.0)))
+
+	if err := h.Execute(NewRemoveCharacterCommand(c.ID)); err != nil {
+		t.Fatalf("Failed to delete character: %v", err)
+	}
+	if len(p.Recordings) != 0 {
+		t.Errorf("Expected 0 recordings, got %d", len(p.Recordings))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected audio moved to trash")
+	}
+	h.Undo()
+	if len(p.Characters) != 1 || len(p.Recordings) != 1 {
+		t.Errorf("Expected character and recording restored, got %d and %d", len(p.Characters), len(p.Recordings))
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected audio restored: %v", err)
+	}
+}
+
+func TestHistoryDepth(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProject("Test", dir)
+	h := NewHistory(p, 2)
+	path := filepath.Join(dir, "take.wav")
+	os.WriteFile(path, []byte("audio"), 0644)
+	r := NewRecording("char-1", path, 0, 1.0)
+	p.AddRecording(r)
+	h.Execute(NewRemoveRecordingCommand(r.ID))
+	h.Execute(NewRenameProjectCommand("A"))
+	h.Execute(NewRenameProjectCommand("B"))
+
+	if n := len(h.State().Undo); n != 2 {
+		t.Errorf("Expected 2 undo entries, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trash", "take.wav")); !os.IsNotExist(err) {
+		t.Error("Expected trashed audio deleted once its command fell off the history")
+	}
+}
+
+func TestHistoryMergesSliderSteps(t *testing.T) {
+	p := NewProject("Test", t.TempDir())
+	h := NewHistory(p, 0)
+	r := NewRecording("char-1", "/take.wav", 0, 1)
+	h.Execute(NewAddRecordingCommand(r))
+
+	for _, v := range []float64{0.9, 0.8, 0.7, 0.6} {
+		h.Execute(NewRecordingVolumeCommand(r.ID, v))
+	}
+	for _, g := range []float64{1, 2, 3} {
+		h.Execute(NewRecordingGainCommand(r.ID, g))
+	}
+	if n := len(h.State().Undo); n != 3 {
+		t.Fatalf("Expected one entry per drag plus the add, got %d", n)
+	}
+	h.Undo()
+	if r.GainDB != 0 || r.Volume != 0.6 {
+		t.Errorf("Expected undo to revert the whole gain drag, got gain %v volume %v", r.GainDB, r.Volume)
+	}
+	h.Undo()
+	if r.Volume != 1 {
+		t.Errorf("Expected undo to revert the whole volume drag, got %v", r.Volume)
+	}
+
+	h.Redo()
+	h.Redo()
+	later := NewRecordingGainCommand(r.ID, 4)
+	later.(*recordingGainCommand).at = time.Now().Add(2 * HistoryMergeWindow)
+	h.Execute(later)
+	if n := len(h.State().Undo); n != 4 {
+		t.Errorf("Expected a later drag to get its own entry, got %d entries", n)
+	}
+}
+
+func TestProjectPurgeTrash(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProject("Test", dir)
+	kept := filepath.Join(dir, "recordings", "kept.wav")
+	p.AddRecording(NewRecording("char-1", kept, 0, 1))
+	os.MkdirAll(filepath.Join(dir, "trash"), 0755)
+	os.WriteFile(filepath.Join(dir, "trash", "kept.wav"), []byte("audio"), 0644)
+	os.WriteFile(filepath.Join(dir, "trash", "old.wav"), []byte("audio"), 0644)
+
+	if err := p.PurgeTrash(); err != nil {
+		t.Fatalf("Failed to purge trash: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trash", "old.wav")); !os.IsNotExist(err) {
+		t.Error("Expected unreferenced audio to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trash", "kept.wav")); err != nil {
+		t.Errorf("Expected audio a recording refers to to be kept: %v", err)
+	}
+	if err := NewProject("Empty", t.TempDir()).PurgeTrash(); err != nil {
+		t.Errorf("Expected no error without a trash folder, got %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package core
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"math"
@@ -9,22 +8,24 @@ import (
 	"path/filepath"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gen2brain/malgo"
 	"github.com/go-audio/audio"
 	"github.com/go-audio/wav"
 	"github.com/google/uuid"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type Microphone struct {
+	backend        CaptureBackend
 	selectedDevice DeviceInfo
 	devices        []DeviceInfo
+	devMu          sync.Mutex
 	filePath       string
-	ctx            context.Context
+	events         EventSink
 	isRecording    bool
 	stopChan       chan struct{}
+	format         AudioFormat
 	mu             sync.Mutex
 	vizBuffer      []int16
 	vizMu          sync.Mutex
@@ -33,15 +34,20 @@ type Microphone struct {
 }
 
 type DeviceInfo struct {
-	DevicesName string         `json:"devices_name"`
-	DeviceID    malgo.DeviceID `json:"-"`
-	UUID        string         `json:"id"`
+	DevicesName string `json:"devices_name"`
+	DeviceID    string `json:"-"`
+	UUID        string `json:"id"`
 }
 
-func NewMicrophone(ctx context.Context) *Microphone {
+func NewMicrophone(events EventSink, backend CaptureBackend) *Microphone {
+	if events == nil {
+		events = NopEventSink{}
+	}
 	mc := &Microphone{
-		ctx:      ctx,
+		backend:  backend,
+		events:   events,
 		stopChan: make(chan struct{}),
+		format:   DefaultAudioFormat(),
 	}
 	list := mc.List()
 	if len(list) > 0 {
@@ -55,32 +61,35 @@ func (m *Microphone) SetFilePath(path string) {
 }
 
 func (m *Microphone) List() []DeviceInfo {
-	devices := make([]DeviceInfo, 0)
-	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	devices, err := m.listDevices()
 	if err != nil {
-		return devices
+		return make([]DeviceInfo, 0)
 	}
-	defer ctx.Uninit()
+	m.devMu.Lock()
+	m.devices = devices
+	m.devMu.Unlock()
+	return devices
+}
 
-	infos, err := ctx.Devices(malgo.Capture)
+func (m *Microphone) listDevices() ([]DeviceInfo, error) {
+	infos, err := m.backend.Devices()
 	if err != nil {
-		return devices
+		return nil, err
 	}
-
+	devices := make([]DeviceInfo, 0, len(infos))
 	for _, info := range infos {
-		full, _ := ctx.DeviceInfo(malgo.Capture, info.ID, malgo.Shared)
-		uid := uuid.NewString()
 		devices = append(devices, DeviceInfo{
-			DevicesName: full.Name(),
+			DevicesName: info.Name,
 			DeviceID:    info.ID,
-			UUID:        uid,
+			UUID:        stableDeviceID(info),
 		})
 	}
-	m.devices = devices
-	return devices
+	return devices, nil
 }
 
 func (m *Microphone) SelectDevice(deviceID string) DeviceInfo {
+	m.devMu.Lock()
+	defer m.devMu.Unlock()
 	deviceIndex := slices.IndexFunc(m.devices, func(d DeviceInfo) bool {
 		return d.UUID == deviceID
 	})
@@ -91,10 +100,54 @@ func (m *Microphone) SelectDevice(deviceID string) DeviceInfo {
 	return m.selectedDevice
 }
 
+// SelectDeviceByName selects the first device with the given name, for
+// settings that outlive the backend's device IDs.
+func (m *Microphone) SelectDeviceByName(name string) DeviceInfo {
+	m.devMu.Lock()
+	empty := len(m.devices) == 0
+	m.devMu.Unlock()
+	if empty {
+		m.List()
+	}
+	m.devMu.Lock()
+	defer m.devMu.Unlock()
+	deviceIndex := slices.IndexFunc(m.devices, func(d DeviceInfo) bool {
+		return d.DevicesName == name
+	})
+	if deviceIndex == -1 {
+		return DeviceInfo{}
+	}
+	m.selectedDevice = m.devices[deviceIndex]
+	return m.selectedDevice
+}
+
 func (m *Microphone) GetSelectedDevice() DeviceInfo {
+	m.devMu.Lock()
+	defer m.devMu.Unlock()
 	return m.selectedDevice
 }
 
+// SetAudioFormat sets the format of the next take. It is rejected while a
+// take is in progress.
+func (m *Microphone) SetAudioFormat(f AudioFormat) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.isRecording {
+		return fmt.Errorf("cannot change the audio format while recording")
+	}
+	m.format = f
+	return nil
+}
+
+func (m *Microphone) GetAudioFormat() AudioFormat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.format
+}
+
 func (m *Microphone) SetInputGain(gainDB float64) {
 	m.gainMu.Lock()
 	defer m.gainMu.Unlock()
@@ -112,12 +165,6 @@ func DBToLinear(db float64) float64 {
 }
 
 func (m *Microphone) Record() bool {
-	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
-	if err != nil {
-		return false
-	}
-	defer ctx.Uninit()
-
 	file, err := os.Create(m.filePath + "/test.wav")
 	if err != nil {
 		log.Println("Error creating file:", err)
@@ -128,33 +175,29 @@ func (m *Microphone) Record() bool {
 	defer enc.Close()
 
 	var samples []int16
+	var samplesMu sync.Mutex
 
-	onRecvFrames := func(_, inputSamples []byte, frameCount uint32) {
-		for i := 0; i < len(inputSamples); i += 2 {
+	onRecvFrames := func(inputSamples []byte) {
+		samplesMu.Lock()
+		defer samplesMu.Unlock()
+		for i := 0; i+1 < len(inputSamples); i += 2 {
 			sample := int16(inputSamples[i]) | int16(inputSamples[i+1])<<8
 			samples = append(samples, sample)
 		}
 	}
 
-	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
-	deviceConfig.Capture.Format = malgo.FormatS16
-	deviceConfig.Capture.Channels = 1
-	deviceConfig.Capture.DeviceID = m.selectedDevice.DeviceID.Pointer()
-	deviceConfig.SampleRate = 44100
-
-	callbacks := malgo.DeviceCallbacks{
-		Data: onRecvFrames,
-	}
-
-	device, err := malgo.InitDevice(ctx.Context, deviceConfig, callbacks)
+	stream, err := m.backend.Open(m.GetSelectedDevice().DeviceID, CaptureConfig{SampleRate: 44100, BitDepth: 16, Channels: 1}, onRecvFrames)
 	if err != nil {
 		return false
 	}
+	defer stream.Close()
 
-	device.Start()
+	stream.Start()
 	time.Sleep(5 * time.Second)
-	device.Stop()
+	stream.Stop()
 
+	samplesMu.Lock()
+	defer samplesMu.Unlock()
 	intBuf := &audio.IntBuffer{
 		Data: int16ToInt(samples),
 		Format: &audio.Format{
@@ -164,11 +207,27 @@ func (m *Microphone) Record() bool {
 	}
 
 	enc.Write(intBuf)
-	runtime.EventsEmit(m.ctx, "audio-sample", samples)
+	m.events.Emit(AudioSampleEvent{Peaks: m.downsampleForViz(samples, 128)})
 	return true
 }
 
 func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*Recording, error) {
+	return m.record(dir, characterID, timecode, nil)
+}
+
+// RecordPunch records a punch-in take between punch.In and punch.Out and
+// stops on its own at the out-point.
+func (m *Microphone) RecordPunch(dir, characterID string, punch Punch) (*Recording, error) {
+	if err := punch.Validate(); err != nil {
+		return nil, err
+	}
+	return m.record(dir, characterID, punch.In, &punch)
+}
+
+func (m *Microphone) record(dir, characterID string, timecode float64, punch *Punch) (*Recording, error) {
+	if !m.IsRecording() {
+		m.RefreshDevices()
+	}
 	m.mu.Lock()
 	if m.isRecording {
 		m.mu.Unlock()
@@ -176,91 +235,100 @@ func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*R
 	}
 	m.isRecording = true
 	m.stopChan = make(chan struct{})
+	format := m.format
 	m.mu.Unlock()
 
-	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
-	if err != nil {
-		m.mu.Lock()
-		m.isRecording = false
-		m.mu.Unlock()
-		return nil, err
-	}
-	defer ctx.Uninit()
-
 	recordingID := uuid.NewString()
 	filename := fmt.Sprintf("%s_%d.wav", recordingID[:8], int(timecode*1000))
 	filePath := filepath.Join(dir, filename)
 
-	file, err := os.Create(filePath)
+	wavWriter, err := NewWAVWriter(filePath, format.SampleRate, format.BitDepth, format.Channels)
 	if err != nil {
 		m.mu.Lock()
 		m.isRecording = false
 		m.mu.Unlock()
 		return nil, err
 	}
-
-	enc := wav.NewEncoder(file, 44100, 16, 1, 1)
-
-	var samples []int16
-	var samplesMu sync.Mutex
+	if err := writePartialMarker(filePath, partialRecording{
+		ID:          recordingID,
+		CharacterID: characterID,
+		Timecode:    timecode,
+	}); err != nil {
+		log.Println("Error writing partial recording marker:", err)
+	}
+	writer := newTakeWriter(wavWriter, format)
 
 	m.gainMu.RLock()
 	gainLinear := DBToLinear(m.inputGainDB)
 	m.gainMu.RUnlock()
 
-	onRecvFrames := func(_, inputSamples []byte, frameCount uint32) {
-		samplesMu.Lock()
-		for i := 0; i+1 < len(inputSamples); i += 2 {
-			sample := int16(inputSamples[i]) | int16(inputSamples[i+1])<<8
-			scaled := float64(sample) * gainLinear
-			if scaled > 32767 {
-				scaled = 32767
-			} else if scaled < -32768 {
-				scaled = -32768
-			}
-			samples = append(samples, int16(scaled))
-		}
-		samplesMu.Unlock()
-
+	keepFrom, keepUntil := int64(0), int64(math.MaxInt64)
+	if punch != nil {
+		keepFrom, keepUntil = punch.frames(format.SampleRate)
+	}
+	var captured atomic.Int64
+
+	channels := int64(format.Channels)
+	bytesPerSample := int64(format.BitDepth / 8)
+	scaledBytes := make([]byte, 0, 4096)
+	onRecvFrames := func(inputSamples []byte) {
+		frames := int64(len(inputSamples)) / (channels * bytesPerSample)
+		start := captured.Load()
+		scaledBytes = scaledBytes[:0]
 		m.vizMu.Lock()
-		for i := 0; i+1 < len(inputSamples); i += 2 {
-			sample := int16(inputSamples[i]) | int16(inputSamples[i+1])<<8
-			scaled := float64(sample) * gainLinear
-			if scaled > 32767 {
-				scaled = 32767
-			} else if scaled < -32768 {
-				scaled = -32768
+		for i := int64(0); i < frames; i++ {
+			keep := start+i >= keepFrom && start+i < keepUntil
+			var peak float64
+			for ch := int64(0); ch < channels; ch++ {
+				offset := (i*channels + ch) * bytesPerSample
+				scaled := decodeSample(inputSamples[offset:], format.BitDepth) * gainLinear
+				scaled = math.Max(-1, math.Min(1, scaled))
+				if keep {
+					scaledBytes = appendSample(scaledBytes, scaled, format.BitDepth)
+				}
+				if math.Abs(scaled) > math.Abs(peak) {
+					peak = scaled
+				}
 			}
-			m.vizBuffer = append(m.vizBuffer, int16(scaled))
+			m.vizBuffer = append(m.vizBuffer, int16(floatToPCM(peak, 16)))
 		}
-		if len(m.vizBuffer) > 44100 {
-			m.vizBuffer = m.vizBuffer[len(m.vizBuffer)-44100:]
+		if len(m.vizBuffer) > format.SampleRate {
+			m.vizBuffer = m.vizBuffer[len(m.vizBuffer)-format.SampleRate:]
 		}
 		m.vizMu.Unlock()
+		if len(scaledBytes) > 0 {
+			writer.Push(scaledBytes)
+		}
+		captured.Store(start + frames)
 	}
 
-	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
-	deviceConfig.Capture.Format = malgo.FormatS16
-	deviceConfig.Capture.Channels = 1
-	deviceConfig.Capture.DeviceID = m.selectedDevice.DeviceID.Pointer()
-	deviceConfig.SampleRate = 44100
-
-	callbacks := malgo.DeviceCallbacks{
-		Data: onRecvFrames,
-	}
-
-	device, err := malgo.InitDevice(ctx.Context, deviceConfig, callbacks)
+	stream, err := m.backend.Open(m.GetSelectedDevice().DeviceID, CaptureConfig{
+		SampleRate: format.SampleRate,
+		BitDepth:   format.BitDepth,
+		Channels:   format.Channels,
+	}, onRecvFrames)
 	if err != nil {
-		file.Close()
+		writer.Close()
 		os.Remove(filePath)
+		removePartialMarker(filePath)
 		m.mu.Lock()
 		m.isRecording = false
 		m.mu.Unlock()
 		return nil, err
 	}
 
-	runtime.EventsEmit(m.ctx, "recording-started", characterID)
-	device.Start()
+	m.events.Emit(RecordingStartedEvent{CharacterID: characterID, RecordingID: recordingID})
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		writer.Close()
+		os.Remove(filePath)
+		removePartialMarker(filePath)
+		m.mu.Lock()
+		m.isRecording = false
+		m.mu.Unlock()
+		m.events.Emit(RecordingStoppedEvent{CharacterID: characterID, RecordingID: recordingID})
+		return nil, err
+	}
 
 	ticker := time.NewTicker(2 * time.Second)
 	tickerDone := make(chan struct{})
@@ -272,7 +340,7 @@ func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*R
 				m.vizMu.Lock()
 				if len(m.vizBuffer) > 0 {
 					vizSamples := m.downsampleForViz(m.vizBuffer, 128)
-					runtime.EventsEmit(m.ctx, "audio-sample", vizSamples)
+					m.events.Emit(AudioSampleEvent{Peaks: vizSamples})
 					m.vizBuffer = nil
 				}
 				m.vizMu.Unlock()
@@ -281,27 +349,25 @@ func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*R
 			}
 		}
 	}()
+	if punch != nil {
+		go m.watchPunch(*punch, format.SampleRate, &captured, tickerDone)
+	}
 
 	<-m.stopChan
 	close(tickerDone)
 
-	device.Stop()
-	time.Sleep(100 * time.Millisecond)
+	stream.Stop()
+	stream.Close()
 
-	samplesMu.Lock()
-	actualDuration := float64(len(samples)) / 44100.0
-	intBuf := &audio.IntBuffer{
-		Data: int16ToInt(samples),
-		Format: &audio.Format{
-			SampleRate:  44100,
-			NumChannels: 1,
-		},
+	writeErr := writer.Close()
+	actualDuration := wavWriter.Duration()
+	if writeErr == nil {
+		removePartialMarker(filePath)
+	}
+	if punch != nil && writeErr == nil && wavWriter.Frames() == 0 {
+		os.Remove(filePath)
+		writeErr = fmt.Errorf("punch cancelled before the in-point")
 	}
-	samplesMu.Unlock()
-
-	enc.Write(intBuf)
-	enc.Close()
-	file.Close()
 
 	m.mu.Lock()
 	m.isRecording = false
@@ -311,7 +377,19 @@ func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*R
 	m.vizBuffer = nil
 	m.vizMu.Unlock()
 
-	runtime.EventsEmit(m.ctx, "recording-stopped", characterID)
+	m.events.Emit(RecordingStoppedEvent{
+		CharacterID: characterID,
+		RecordingID: recordingID,
+		Duration:    actualDuration,
+	})
+
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	if punch != nil && captured.Load() >= punch.endFrame(format.SampleRate) {
+		m.events.Emit(PunchFinishedEvent{RecordingID: recordingID})
+	}
 
 	return &Recording{
 		ID:          recordingID,
@@ -326,7 +404,12 @@ func (m *Microphone) RecordToFile(dir, characterID string, timecode float64) (*R
 func (m *Microphone) StopRecording() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if m.isRecording {
+	if !m.isRecording {
+		return
+	}
+	select {
+	case <-m.stopChan:
+	default:
 		close(m.stopChan)
 	}
 }
@@ -374,62 +457,45 @@ func int16ToInt(data []int16) []int {
 	return intData
 }
 
+// GetWaveformPeaks returns numPeaks absolute peaks of a WAV file on a 16-bit
+// scale, whatever its sample format, taking the loudest channel per frame.
 func GetWaveformPeaks(filePath string, numPeaks int) ([]int, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	decoder := wav.NewDecoder(file)
-	if !decoder.IsValidFile() {
-		return nil, fmt.Errorf("invalid WAV file")
-	}
-
-	buf, err := decoder.FullPCMBuffer()
+	pcm, err := readWAVFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-
-	samples := buf.Data
-	if len(samples) == 0 {
+	frames := int(pcm.Frames())
+	if frames == 0 {
 		return []int{}, nil
 	}
 
 	if numPeaks <= 0 {
 		numPeaks = 128
 	}
+	framePeak := func(i int) int {
+		var peak float64
+		for ch := 0; ch < pcm.Channels; ch++ {
+			peak = math.Max(peak, math.Abs(pcm.Samples[i*pcm.Channels+ch]))
+		}
+		return int(math.Min(peak, 1) * 32767)
+	}
 
-	if len(samples) <= numPeaks {
-		result := make([]int, len(samples))
-		for i, v := range samples {
-			if v < 0 {
-				v = -v
-			}
-			result[i] = v
+	if frames <= numPeaks {
+		result := make([]int, frames)
+		for i := range result {
+			result[i] = framePeak(i)
 		}
 		return result, nil
 	}
 
 	result := make([]int, numPeaks)
-	step := len(samples) / numPeaks
+	step := frames / numPeaks
 	for i := 0; i < numPeaks; i++ {
 		start := i * step
-		end := start + step
-		if end > len(samples) {
-			end = len(samples)
-		}
-		var peak int
+		end := min(start+step, frames)
 		for j := start; j < end; j++ {
-			v := samples[j]
-			if v < 0 {
-				v = -v
-			}
-			if v > peak {
-				peak = v
-			}
+			result[i] = max(result[i], framePeak(j))
 		}
-		result[i] = peak
 	}
 	return result, nil
 }
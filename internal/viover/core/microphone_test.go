@@ -0,0 +1,346 @@
+package core
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-audio/wav"
+)
+
+func recordFake(t *testing.T, mic *Microphone, backend *FakeBackend, dir string) *Recording {
+	t.Helper()
+	done := make(chan struct{})
+	var recording *Recording
+	var err error
+	go func() {
+		recording, err = mic.RecordToFile(dir, "char-1", 3.0)
+		close(done)
+	}()
+	select {
+	case <-backend.Exhausted():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for fake backend")
+	}
+	mic.StopRecording()
+	<-done
+	if err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	return recording
+}
+
+func readWAVSamples(t *testing.T, path string) []int {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open recording: %v", err)
+	}
+	defer file.Close()
+	buf, err := wav.NewDecoder(file).FullPCMBuffer()
+	if err != nil {
+		t.Fatalf("Failed to decode recording: %v", err)
+	}
+	return buf.Data
+}
+
+func TestMicrophoneListFakeDevices(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	mic := NewMicrophone(nil, backend)
+	devices := mic.List()
+	if len(devices) != 1 {
+		t.Fatalf("Expected 1 device, got %d", len(devices))
+	}
+	if devices[0].DevicesName != "Fake Microphone" {
+		t.Errorf("Expected 'Fake Microphone', got '%s'", devices[0].DevicesName)
+	}
+	if mic.GetSelectedDevice().DeviceID != "fake-0" {
+		t.Error("Expected first device to be selected by default")
+	}
+}
+
+func TestMicrophoneSelectDeviceByName(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.DeviceList = []CaptureDevice{{ID: "a", Name: "Built-in"}, {ID: "b", Name: "USB Interface"}}
+	mic := NewMicrophone(nil, backend)
+	if d := mic.SelectDeviceByName("USB Interface"); d.DeviceID != "b" {
+		t.Errorf("Expected device 'b', got '%s'", d.DeviceID)
+	}
+	if d := mic.SelectDeviceByName("Unplugged"); d.DeviceID != "" {
+		t.Error("Expected no device for an unknown name")
+	}
+	if mic.GetSelectedDevice().DeviceID != "b" {
+		t.Error("Expected selection to be kept when the name is not found")
+	}
+}
+
+func TestMicrophoneStableDeviceIDs(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.DeviceList = []CaptureDevice{{ID: "a", Name: "Built-in"}, {ID: "b", Name: "USB Interface"}}
+	mic := NewMicrophone(nil, backend)
+	first := mic.List()
+	second := mic.List()
+	if first[1].UUID != second[1].UUID {
+		t.Errorf("Expected the same ID across listings, got '%s' and '%s'", first[1].UUID, second[1].UUID)
+	}
+	if first[0].UUID == first[1].UUID {
+		t.Error("Expected different devices to get different IDs")
+	}
+	if d := mic.SelectDevice(first[1].UUID); d.DeviceID != "b" {
+		t.Errorf("Expected device 'b', got '%s'", d.DeviceID)
+	}
+}
+
+func TestMicrophoneDeviceFallback(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.DeviceList = []CaptureDevice{{ID: "a", Name: "Built-in"}, {ID: "b", Name: "USB Interface"}}
+	events := NewEventRecorder()
+	mic := NewMicrophone(events, backend)
+	mic.SelectDeviceByName("USB Interface")
+
+	mic.RefreshDevices()
+	if len(events.Events()) != 0 {
+		t.Errorf("Expected no events without changes, got %d", len(events.Events()))
+	}
+
+	backend.SetDevices([]CaptureDevice{{ID: "a", Name: "Built-in"}})
+	mic.RefreshDevices()
+	if mic.GetSelectedDevice().DeviceID != "a" {
+		t.Errorf("Expected fallback to device 'a', got '%s'", mic.GetSelectedDevice().DeviceID)
+	}
+	if len(events.Named("devices-changed")) != 1 {
+		t.Errorf("Expected one devices-changed event, got %d", len(events.Named("devices-changed")))
+	}
+	fallbacks := events.Named("device-fallback")
+	if len(fallbacks) != 1 {
+		t.Fatalf("Expected one device-fallback event, got %d", len(fallbacks))
+	}
+	fallback := fallbacks[0].(DeviceFallbackEvent)
+	if fallback.Lost.DeviceID != "b" || fallback.Interrupted {
+		t.Errorf("Expected lost device 'b' between takes, got %+v", fallback)
+	}
+}
+
+func TestMicrophoneDeviceLostWhileRecording(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.DeviceList = []CaptureDevice{{ID: "a", Name: "Built-in"}, {ID: "b", Name: "USB Interface"}}
+	events := NewEventRecorder()
+	mic := NewMicrophone(events, backend)
+	mic.SelectDeviceByName("USB Interface")
+	stop := mic.WatchDevices(10 * time.Millisecond)
+	defer stop()
+
+	done := make(chan struct{})
+	var recording *Recording
+	var err error
+	go func() {
+		recording, err = mic.RecordToFile(t.TempDir(), "char-1", 0)
+		close(done)
+	}()
+	for !mic.IsRecording() {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	backend.SetDevices([]CaptureDevice{{ID: "a", Name: "Built-in"}})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the take to stop when its device was removed")
+	}
+	if err != nil {
+		t.Fatalf("Expected the partial take to be kept, got %v", err)
+	}
+	if recording.Duration <= 0 {
+		t.Errorf("Expected a non-empty take, got duration %f", recording.Duration)
+	}
+	fallbacks := events.Named("device-fallback")
+	if len(fallbacks) != 1 || !fallbacks[0].(DeviceFallbackEvent).Interrupted {
+		t.Errorf("Expected one interrupting device-fallback event, got %v", fallbacks)
+	}
+}
+
+func TestMicrophoneRecordToFile(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.Interval = 0
+	backend.MaxFrames = 44100
+	events := NewEventRecorder()
+	mic := NewMicrophone(events, backend)
+	dir := t.TempDir()
+
+	r := recordFake(t, mic, backend, dir)
+	started := events.Named("recording-started")
+	if len(started) != 1 || started[0].(RecordingStartedEvent).RecordingID != r.ID {
+		t.Errorf("Expected one recording-started event for %s, got %v", r.ID, started)
+	}
+	stopped := events.Named("recording-stopped")
+	if len(stopped) != 1 || stopped[0].(RecordingStoppedEvent).Duration != r.Duration {
+		t.Errorf("Expected one recording-stopped event with the take duration, got %v", stopped)
+	}
+	if r.Duration != 1.0 {
+		t.Errorf("Expected duration 1.0, got %f", r.Duration)
+	}
+	if r.Timecode != 3.0 || r.CharacterID != "char-1" {
+		t.Errorf("Unexpected recording: %+v", r)
+	}
+	if mic.IsRecording() {
+		t.Error("Expected recording to be stopped")
+	}
+	if _, err := os.Stat(r.FilePath + partialSuffix); !os.IsNotExist(err) {
+		t.Error("Expected partial marker to be removed after a clean stop")
+	}
+	samples := readWAVSamples(t, r.FilePath)
+	if len(samples) != 44100 {
+		t.Fatalf("Expected 44100 samples, got %d", len(samples))
+	}
+	source := SineSource(440, 1000, 44100)
+	for _, i := range []int{0, 25, 1000, 44099} {
+		if samples[i] != int(source(int64(i))) {
+			t.Errorf("Sample %d: expected %d, got %d", i, source(int64(i)), samples[i])
+		}
+	}
+}
+
+func TestMicrophoneRecordAudioFormat(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 48000))
+	backend.Interval = 0
+	backend.MaxFrames = 48000
+	mic := NewMicrophone(nil, backend)
+	format := AudioFormat{SampleRate: 48000, BitDepth: 24, Channels: 2}
+	if err := mic.SetAudioFormat(format); err != nil {
+		t.Fatalf("Failed to set audio format: %v", err)
+	}
+
+	r := recordFake(t, mic, backend, t.TempDir())
+	if r.Duration != 1.0 {
+		t.Errorf("Expected duration 1.0, got %f", r.Duration)
+	}
+	pcm, err := readWAVFile(r.FilePath)
+	if err != nil {
+		t.Fatalf("Failed to read recording: %v", err)
+	}
+	if pcm.SampleRate != 48000 || pcm.BitDepth != 24 || pcm.Channels != 2 {
+		t.Errorf("Expected 48000/24/2, got %d/%d/%d", pcm.SampleRate, pcm.BitDepth, pcm.Channels)
+	}
+	source := SineSource(440, 1000, 48000)
+	for _, i := range []int{25, 1000, 47999} {
+		want := float64(source(int64(i))) / 32768
+		if pcm.Samples[2*i] != want || pcm.Samples[2*i+1] != want {
+			t.Errorf("Frame %d: expected %f on both channels, got %f and %f", i, want, pcm.Samples[2*i], pcm.Samples[2*i+1])
+		}
+	}
+	if err := mic.SetAudioFormat(AudioFormat{SampleRate: 8000, BitDepth: 16, Channels: 1}); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
+
+func TestMicrophoneInputGain(t *testing.T) {
+	backend := NewFakeBackend(func(int64) int16 { return 1000 })
+	backend.Interval = 0
+	backend.MaxFrames = 4410
+	mic := NewMicrophone(nil, backend)
+	mic.SetInputGain(20 * 0.30103)
+
+	r := recordFake(t, mic, backend, t.TempDir())
+	samples := readWAVSamples(t, r.FilePath)
+	if samples[0] < 1995 || samples[0] > 2001 {
+		t.Errorf("Expected sample near 2000 after +6dB, got %d", samples[0])
+	}
+
+	mic.SetInputGain(40)
+	if mic.GetInputGain() != 12.0 {
+		t.Errorf("Expected gain clamped to 12, got %f", mic.GetInputGain())
+	}
+}
+
+func TestMicrophoneDownsampleForViz(t *testing.T) {
+	mic := NewMicrophone(nil, NewFakeBackend(SineSource(440, 1000, 44100)))
+	samples := make([]int16, 1024)
+	samples[10] = -500
+	samples[900] = 300
+	peaks := mic.downsampleForViz(samples, 128)
+	if len(peaks) != 128 {
+		t.Fatalf("Expected 128 peaks, got %d", len(peaks))
+	}
+	if peaks[1] != 500 {
+		t.Errorf("Expected peak 500, got %d", peaks[1])
+	}
+	if peaks[112] != 300 {
+		t.Errorf("Expected peak 300, got %d", peaks[112])
+	}
+}
+
+func TestMicrophoneEmitsAudioSamples(t *testing.T) {
+	backend := NewFakeBackend(SineSource(440, 1000, 44100))
+	backend.ChunkFrames = 4410
+	backend.Interval = 100 * time.Millisecond
+	backend.MaxFrames = 44100 * 3
+	events := NewEventRecorder()
+	mic := NewMicrophone(events, backend)
+
+	recordFake(t, mic, backend, t.TempDir())
+	samples := events.Named("audio-sample")
+	if len(samples) == 0 {
+		t.Fatal("Expected at least one audio-sample event")
+	}
+	peaks := samples[0].(AudioSampleEvent).Peaks
+	if len(peaks) != 128 {
+		t.Fatalf("Expected 128 peaks, got %d", len(peaks))
+	}
+	if peaks[0] < 900 || peaks[0] > 1000 {
+		t.Errorf("Expected peak near 1000, got %d", peaks[0])
+	}
+}
+
+func TestMicrophoneRecordPunch(t *testing.T) {
+	source := SineSource(440, 1000, 44100)
+	var lastFrame atomic.Int64
+	backend := NewFakeBackend(func(frame int64) int16 {
+		lastFrame.Store(max(lastFrame.Load(), frame))
+		return source(frame)
+	})
+	events := NewEventRecorder()
+	mic := NewMicrophone(events, backend)
+
+	r, err := mic.RecordPunch(t.TempDir(), "char-1", Punch{In: 10, Out: 10.5, PreRoll: 1.2, PostRoll: 0.05})
+	if err != nil {
+		t.Fatalf("Failed to record punch: %v", err)
+	}
+	if r.Timecode != 10 {
+		t.Errorf("Expected take at the in-point, got %f", r.Timecode)
+	}
+	if r.Duration != 0.5 {
+		t.Errorf("Expected duration 0.5, got %f", r.Duration)
+	}
+	samples := readWAVSamples(t, r.FilePath)
+	if samples[0] != int(source(52920)) || samples[100] != int(source(53020)) {
+		t.Error("Expected take to start exactly at the end of the pre-roll")
+	}
+	for _, name := range []string{"punch-in", "punch-out", "punch-finished"} {
+		if len(events.Named(name)) != 1 {
+			t.Errorf("Expected one %s event, got %d", name, len(events.Named(name)))
+		}
+	}
+	if lastFrame.Load() < 77174 {
+		t.Errorf("Expected capture to run through the post-roll, stopped at frame %d", lastFrame.Load())
+	}
+	order := make([]string, 0)
+	for _, e := range events.Events() {
+		switch e.EventName() {
+		case "punch-out", "recording-stopped", "punch-finished":
+			order = append(order, e.EventName())
+		}
+	}
+	if len(order) != 3 || order[0] != "punch-out" || order[2] != "punch-finished" {
+		t.Errorf("Expected punch-out, recording-stopped, punch-finished, got %v", order)
+	}
+	countdown := events.Named("punch-countdown")
+	if len(countdown) == 0 || countdown[0].(PunchCountdownEvent).Remaining != 2 {
+		t.Errorf("Expected countdown to start at 2, got %v", countdown)
+	}
+
+	if _, err := mic.RecordPunch(t.TempDir(), "char-1", Punch{In: 5, Out: 4}); err == nil {
+		t.Error("Expected error for out-point before in-point")
+	}
+}
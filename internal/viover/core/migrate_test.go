@@ -0,0 +1,101 @@
+package core
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStoreUpgradesLegacyDatabase(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(tmpDir, "viover.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+	CREATE TABLE projects (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		path TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO projects (id, title, path) VALUES ('old-1', 'Old Project', '/tmp/old');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+	db.Close()
+
+	store, err := NewStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to open legacy store: %v", err)
+	}
+	defer store.Close()
+	if p := store.GetProject("old-1"); p == nil || p.Title != "Old Project" {
+		t.Errorf("Expected legacy project to survive the upgrade, got %+v", p)
+	}
+	var count int
+	store.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	if count == 0 {
+		t.Error("Expected migrations to be recorded")
+	}
+}
+
+func TestMigrateDBAppliesPendingMigrations(t *testing.T) {
+	db, _ := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	defer db.Close()
+	v1 := fstest.MapFS{
+		"0001_init.sql": {Data: []byte(`CREATE TABLE items (id TEXT PRIMARY KEY);`)},
+	}
+	if err := migrateDB(db, v1); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.Exec(`INSERT INTO items (id) VALUES ('a')`)
+
+	v2 := fstest.MapFS{
+		"0001_init.sql":     v1["0001_init.sql"],
+		"0002_add_name.sql": {Data: []byte(`ALTER TABLE items ADD COLUMN name TEXT NOT NULL DEFAULT 'unnamed';`)},
+	}
+	if err := migrateDB(db, v2); err != nil {
+		t.Fatalf("Failed to upgrade: %v", err)
+	}
+	var name string
+	if err := db.QueryRow(`SELECT name FROM items WHERE id = 'a'`).Scan(&name); err != nil || name != "unnamed" {
+		t.Errorf("Expected 'unnamed', got '%s' (%v)", name, err)
+	}
+	if err := migrateDB(db, v2); err != nil {
+		t.Errorf("Expected rerunning migrations to be a no-op, got %v", err)
+	}
+}
+
+func TestMigrateDBRollsBackFailedMigration(t *testing.T) {
+	db, _ := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	defer db.Close()
+	broken := fstest.MapFS{
+		"0001_init.sql":   {Data: []byte(`CREATE TABLE items (id TEXT PRIMARY KEY);`)},
+		"0002_broken.sql": {Data: []byte(`CREATE TABLE half (id TEXT); NOT VALID SQL;`)},
+	}
+	if err := migrateDB(db, broken); err == nil {
+		t.Fatal("Expected the broken migration to fail")
+	}
+	var version int
+	db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if version != 1 {
+		t.Errorf("Expected version 1 recorded, got %d", version)
+	}
+	if _, err := db.Exec(`SELECT * FROM half`); err == nil {
+		t.Error("Expected the failed migration's changes to be rolled back")
+	}
+}
+
+func TestLoadMigrationsRejectsBadNames(t *testing.T) {
+	if _, err := loadMigrations(fstest.MapFS{"init.sql": {}}); err == nil {
+		t.Error("Expected error for unnumbered migration")
+	}
+	dup := fstest.MapFS{"0001_a.sql": {}, "0001_b.sql": {}}
+	if _, err := loadMigrations(dup); err == nil {
+		t.Error("Expected error for duplicate version")
+	}
+}
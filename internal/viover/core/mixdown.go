@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+const mixBlockFrames = 65536
+
+type MixOptions struct {
+	Length           float64
+	CharacterVolumes map[string]float64
+	MasterVolume     float64
+	CharacterID      string
+}
+
+// mixSource is a take converted to the project's format. start is in frames
+// and samples are interleaved.
+type mixSource struct {
+	start   int64
+	samples []float64
+}
+
+// RenderMix places every active take at its timecode on a silent bed and
+// writes the summed result to dst in the project's audio format. Length is
+// the bed length in seconds and defaults to the project's timeline length.
+// When CharacterID is set only that character's takes are mixed.
+func (p *Project) RenderMix(opts MixOptions, dst, format string) error {
+	af := p.Format()
+	sources := p.loadMixSources(opts, af)
+
+	length := opts.Length
+	if length <= 0 {
+		length = p.TimelineLength()
+	}
+	totalFrames := int64(math.Ceil(length * float64(af.SampleRate)))
+	channels := int64(af.Channels)
+
+	enc, err := newPCMEncoder(dst, format, af.SampleRate, af.BitDepth, af.Channels, totalFrames)
+	if err != nil {
+		return err
+	}
+	block := make([]float64, mixBlockFrames*channels)
+	for pos := int64(0); pos < totalFrames; pos += mixBlockFrames {
+		n := min(int64(mixBlockFrames), totalFrames-pos)
+		out := block[:n*channels]
+		clear(out)
+		for _, src := range sources {
+			srcFrames := int64(len(src.samples)) / channels
+			from := max(pos, src.start)
+			to := min(pos+n, src.start+srcFrames)
+			for i := from * channels; i < to*channels; i++ {
+				out[i-pos*channels] += src.samples[i-src.start*channels]
+			}
+		}
+		if err := enc.Write(out); err != nil {
+			enc.Close()
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// RenderStems renders one full-length stem per character into dir. Every
+// stem starts at zero and shares the project's timeline length so they line
+// up when dropped into a session. It returns the written file paths.
+func (p *Project) RenderStems(opts MixOptions, dir, format string) ([]string, error) {
+	if opts.Length <= 0 {
+		opts.Length = p.TimelineLength()
+	}
+	paths := make([]string, 0, len(p.Characters))
+	used := make(map[string]bool, len(p.Characters))
+	for _, c := range p.Characters {
+		stemOpts := opts
+		stemOpts.CharacterID = c.ID
+		dst := filepath.Join(dir, stemFilename(p.Title, c.Name, format, used))
+		if err := p.RenderMix(stemOpts, dst, format); err != nil {
+			return paths, err
+		}
+		paths = append(paths, dst)
+	}
+	return paths, nil
+}
+
+// stemFilename names a character's stem, numbering characters whose names
+// sanitize to one already in used. Names are compared case-insensitively
+// for filesystems that ignore case.
+func stemFilename(title, character, format string, used map[string]bool) string {
+	base := SanitizeFilename(title + "_" + character)
+	name := base
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[strings.ToLower(name)] = true
+	return name + "_stem." + format
+}
+
+func (p *Project) loadMixSources(opts MixOptions, af AudioFormat) []mixSource {
+	sources := make([]mixSource, 0)
+	for _, r := range p.ActiveRecordings() {
+		if opts.CharacterID != "" && r.CharacterID != opts.CharacterID {
+			continue
+		}
+		pcm, err := readWAVFile(r.FilePath)
+		if err != nil {
+			log.Println("Mixdown error:", err)
+			continue
+		}
+		charVol := 1.0
+		if v, ok := opts.CharacterVolumes[r.CharacterID]; ok {
+			charVol = v
+		}
+		gain := opts.MasterVolume * charVol * r.LinearGain()
+		samples := convertPCM(pcm, af.SampleRate, af.Channels)
+		for i := range samples {
+			samples[i] *= gain
+		}
+		sources = append(sources, mixSource{
+			start:   int64(math.Round(r.Timecode * float64(af.SampleRate))),
+			samples: samples,
+		})
+	}
+	return sources
+}
+
+func downmixToMono(pcm *pcmData) []float64 {
+	if pcm.Channels == 1 {
+		return pcm.Samples
+	}
+	mono := make([]float64, pcm.Frames())
+	for i := range mono {
+		var sum float64
+		for ch := 0; ch < pcm.Channels; ch++ {
+			sum += pcm.Samples[i*pcm.Channels+ch]
+		}
+		mono[i] = sum / float64(pcm.Channels)
+	}
+	return mono
+}
+
+// resampleLinear resamples interleaved samples with the given channel count.
+func resampleLinear(samples []float64, channels, from, to int) []float64 {
+	frames := len(samples) / channels
+	if from == to || frames == 0 {
+		return samples
+	}
+	outFrames := int(int64(frames) * int64(to) / int64(from))
+	out := make([]float64, outFrames*channels)
+	ratio := float64(from) / float64(to)
+	for i := 0; i < outFrames; i++ {
+		pos := float64(i) * ratio
+		j := int(pos)
+		frac := pos - float64(j)
+		next := j + 1
+		if next >= frames {
+			next = frames - 1
+		}
+		for ch := 0; ch < channels; ch++ {
+			out[i*channels+ch] = samples[j*channels+ch]*(1-frac) + samples[next*channels+ch]*frac
+		}
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/flac"
+)
+
+func writeConstantWAV(t *testing.T, path string, value float64, frames int) {
+	t.Helper()
+	enc, err := newWAVEncoder(path, 44100, 16, 1)
+	if err != nil {
+		t.Fatalf("Failed to create WAV: %v", err)
+	}
+	samples := make([]float64, frames)
+	for i := range samples {
+		samples[i] = value
+	}
+	enc.Write(samples)
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Failed to close WAV: %v", err)
+	}
+}
+
+func TestProjectRenderMix(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Mix", tmpDir)
+	alice := NewCharacter("Alice", "#ff0000")
+	bob := NewCharacter("Bob", "#0000ff")
+	p.AddCharacter(alice)
+	p.AddCharacter(bob)
+
+	aPath := filepath.Join(tmpDir, "a.wav")
+	bPath := filepath.Join(tmpDir, "b.wav")
+	writeConstantWAV(t, aPath, 0.25, 44100)
+	writeConstantWAV(t, bPath, 0.25, 44100)
+	a := NewRecording(alice.ID, aPath, 1.0, 1.0)
+	b := NewRecording(bob.ID, bPath, 1.5, 1.0)
+	b.GainDB = 20 * 0.30103
+	p.AddRecording(a)
+	p.AddRecording(b)
+
+	dst := filepath.Join(tmpDir, "mix.wav")
+	err := p.RenderMix(MixOptions{
+		Length:           4.0,
+		CharacterVolumes: map[string]float64{alice.ID: 0.5},
+		MasterVolume:     1.0,
+	}, dst, "wav")
+	if err != nil {
+		t.Fatalf("Failed to render mix: %v", err)
+	}
+
+	samples := readWAVSamples(t, dst)
+	if len(samples) != 4*44100 {
+		t.Fatalf("Expected a 4 second bed, got %d samples", len(samples))
+	}
+	expect := map[int]int{
+		0:           0,
+		44100:       4096,
+		66150:       4096 + 16384,
+		2*44100 + 1: 16384,
+		3 * 44100:   0,
+	}
+	for i, want := range expect {
+		if diff := samples[i] - want; diff < -2 || diff > 2 {
+			t.Errorf("Sample %d: expected %d, got %d", i, want, samples[i])
+		}
+	}
+}
+
+func TestProjectRenderMixFLAC(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Mix", tmpDir)
+	path := filepath.Join(tmpDir, "a.wav")
+	writeConstantWAV(t, path, 0.5, 10000)
+	p.AddRecording(NewRecording("char-1", path, 0.5, 10000.0/44100))
+
+	dst := filepath.Join(tmpDir, "mix.flac")
+	if err := p.RenderMix(MixOptions{MasterVolume: 1.0}, dst, "flac"); err != nil {
+		t.Fatalf("Failed to render FLAC mix: %v", err)
+	}
+	stream, err := flac.ParseFile(dst)
+	if err != nil {
+		t.Fatalf("Failed to parse FLAC: %v", err)
+	}
+	defer stream.Close()
+	if stream.Info.NSamples != 22050+10000 {
+		t.Errorf("Expected %d samples, got %d", 22050+10000, stream.Info.NSamples)
+	}
+}
+
+func TestProjectRenderMixAudioFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Mix", tmpDir)
+	p.AudioFormat = AudioFormat{SampleRate: 48000, BitDepth: 32, Channels: 2}
+	path := filepath.Join(tmpDir, "a.wav")
+	writeConstantWAV(t, path, 0.25, 44100)
+	p.AddRecording(NewRecording("char-1", path, 1.0, 1.0))
+
+	dst := filepath.Join(tmpDir, "mix.wav")
+	if err := p.RenderMix(MixOptions{Length: 3.0, MasterVolume: 1.0}, dst, "wav"); err != nil {
+		t.Fatalf("Failed to render mix: %v", err)
+	}
+	pcm, err := readWAVFile(dst)
+	if err != nil {
+		t.Fatalf("Failed to read mix: %v", err)
+	}
+	if pcm.SampleRate != 48000 || pcm.BitDepth != 32 || pcm.Channels != 2 {
+		t.Errorf("Expected 48000/32/2, got %d/%d/%d", pcm.SampleRate, pcm.BitDepth, pcm.Channels)
+	}
+	if pcm.Frames() != 3*48000 {
+		t.Fatalf("Expected %d frames, got %d", 3*48000, pcm.Frames())
+	}
+	for _, frame := range []int{100, 48000 + 100, 2*48000 + 100} {
+		want := 0.0
+		if frame > 48000 && frame < 2*48000 {
+			want = 0.25
+		}
+		for ch := 0; ch < 2; ch++ {
+			if got := pcm.Samples[frame*2+ch]; got != want {
+				t.Errorf("Frame %d channel %d: expected %f, got %f", frame, ch, want, got)
+			}
+		}
+	}
+}
+
+func TestProjectRenderStems(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Stems", tmpDir)
+	alice := NewCharacter("Alice", "#ff0000")
+	bob := NewCharacter("Bob", "#0000ff")
+	p.AddCharacter(alice)
+	p.AddCharacter(bob)
+	aPath := filepath.Join(tmpDir, "a.wav")
+	bPath := filepath.Join(tmpDir, "b.wav")
+	writeConstantWAV(t, aPath, 0.25, 44100)
+	writeConstantWAV(t, bPath, 0.25, 44100)
+	p.AddRecording(NewRecording(alice.ID, aPath, 0.5, 1.0))
+	p.AddRecording(NewRecording(bob.ID, bPath, 2.0, 1.0))
+
+	paths, err := p.RenderStems(MixOptions{
+		CharacterVolumes: map[string]float64{bob.ID: 0.5},
+		MasterVolume:     1.0,
+	}, tmpDir, "wav")
+	if err != nil {
+		t.Fatalf("Failed to render stems: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 stems, got %d", len(paths))
+	}
+	if filepath.Base(paths[0]) != "Stems_Alice_stem.wav" {
+		t.Errorf("Unexpected stem name: %s", filepath.Base(paths[0]))
+	}
+
+	used := map[string]bool{}
+	for i, want := range []string{"Dub_A_B_stem.wav", "Dub_A_B_2_stem.wav", "Dub_a_b_3_stem.wav"} {
+		if got := stemFilename("Dub", []string{"A/B", "A:B", "a?b"}[i], "wav", used); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+
+	aliceStem := readWAVSamples(t, paths[0])
+	bobStem := readWAVSamples(t, paths[1])
+	if len(aliceStem) != 3*44100 || len(bobStem) != 3*44100 {
+		t.Fatalf("Expected both stems to span the full timeline, got %d and %d", len(aliceStem), len(bobStem))
+	}
+	if aliceStem[44100] != 8192 || aliceStem[2*44100+100] != 0 {
+		t.Error("Expected Alice's stem to contain only her take")
+	}
+	if bobStem[44100] != 0 || bobStem[2*44100+100] != 4096 {
+		t.Error("Expected Bob's stem to contain only his take at half volume")
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	MuxReplace = "replace"
+	MuxMix     = "mix"
+	MuxAdd     = "add"
+)
+
+// MuxOptions controls how a voice track is combined with a video's own
+// audio. OriginalLevelDB ducks the original audio when mixing; SampleRate,
+// when set, is the rate of the encoded audio. VideoCodec is the source's
+// codec as reported by ffprobe. Duration, when set, is the video's length;
+// the output is cut there so audio never runs past the picture.
+type MuxOptions struct {
+	Mode            string
+	OriginalLevelDB float64
+	SampleRate      int
+	VideoCodec      string
+	Duration        float64
+}
+
+// mp4VideoCodecs are the codecs an MP4 container can carry as they are.
+var mp4VideoCodecs = map[string]bool{
+	"h264":  true,
+	"hevc":  true,
+	"mpeg4": true,
+	"av1":   true,
+	"vp9":   true,
+}
+
+// MuxVideo writes a copy of videoPath to dst, an MP4, with voicePath as its
+// audio. The video stream is copied untouched when MP4 can hold it and
+// re-encoded to H.264 otherwise, such as ProRes or DNxHD from a .mov; audio
+// is encoded to AAC.
+func MuxVideo(videoPath, voicePath, dst string, opts MuxOptions) error {
+	args, err := muxArgs(videoPath, voicePath, dst, opts)
+	if err != nil {
+		return err
+	}
+	ffmpegPath := findFFmpeg()
+	cmd := exec.Command(ffmpegPath, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg error: %w (ffmpeg path: %s): %s", err, ffmpegPath, lastLine(string(out)))
+	}
+	return nil
+}
+
+func muxArgs(videoPath, voicePath, dst string, opts MuxOptions) ([]string, error) {
+	args := []string{"-y", "-i", videoPath, "-i", voicePath}
+	switch opts.Mode {
+	case MuxReplace, "":
+		args = append(args, "-map", "0:v", "-map", "1:a")
+	case MuxMix:
+		filter := fmt.Sprintf("[0:a]volume=%.2fdB[orig];[orig][1:a]amix=inputs=2:duration=first:dropout_transition=0:normalize=0[aout]", opts.OriginalLevelDB)
+		args = append(args, "-filter_complex", filter, "-map", "0:v", "-map", "[aout]")
+	case MuxAdd:
+		args = append(args, "-map", "0:v", "-map", "0:a?", "-map", "1:a")
+	default:
+		return nil, fmt.Errorf("unsupported mux mode: %s", opts.Mode)
+	}
+	if opts.VideoCodec == "" || mp4VideoCodecs[opts.VideoCodec] {
+		args = append(args, "-c:v", "copy")
+	} else {
+		args = append(args, "-c:v", "libx264", "-preset", "medium", "-crf", "18", "-pix_fmt", "yuv420p")
+	}
+	args = append(args, "-c:a", "aac", "-b:a", "192k")
+	if opts.SampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(opts.SampleRate))
+	}
+	if opts.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(opts.Duration, 'f', 3, 64))
+	}
+	args = append(args, dst)
+	return args, nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
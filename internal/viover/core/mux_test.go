@@ -0,0 +1,65 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMuxArgs(t *testing.T) {
+	replace, err := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{Mode: MuxReplace})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Contains(replace, "1:a") || slices.Contains(replace, "0:a?") {
+		t.Errorf("Expected replace to map only the voice track, got %v", replace)
+	}
+
+	mix, _ := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{Mode: MuxMix, OriginalLevelDB: -12})
+	i := slices.Index(mix, "-filter_complex")
+	if i == -1 || !strings.Contains(mix[i+1], "volume=-12.00dB") {
+		t.Errorf("Expected mix to duck the original audio, got %v", mix)
+	}
+
+	add, _ := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{Mode: MuxAdd})
+	if !slices.Contains(add, "0:a?") || !slices.Contains(add, "1:a") {
+		t.Errorf("Expected add to keep both audio streams, got %v", add)
+	}
+	if add[len(add)-1] != "out.mp4" {
+		t.Errorf("Expected destination last, got %v", add)
+	}
+
+	if _, err := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{Mode: "bogus"}); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
+
+func TestMuxArgsDuration(t *testing.T) {
+	for _, mode := range []string{MuxReplace, MuxMix, MuxAdd} {
+		args, _ := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{Mode: mode, Duration: 62.5})
+		i := slices.Index(args, "-t")
+		if i == -1 || args[i+1] != "62.500" || i+2 != len(args)-1 {
+			t.Errorf("Expected %s output cut at the video length, got %v", mode, args)
+		}
+	}
+	args, _ := muxArgs("in.mp4", "voice.wav", "out.mp4", MuxOptions{})
+	if slices.Contains(args, "-t") {
+		t.Errorf("Expected no cut without a known duration, got %v", args)
+	}
+}
+
+func TestMuxArgsVideoCodec(t *testing.T) {
+	for codec, copied := range map[string]bool{"h264": true, "hevc": true, "": true, "prores": false, "dnxhd": false} {
+		args, _ := muxArgs("in.mov", "voice.wav", "out.mp4", MuxOptions{VideoCodec: codec})
+		i := slices.Index(args, "-c:v")
+		if i == -1 {
+			t.Fatalf("Expected a video codec argument, got %v", args)
+		}
+		if got := args[i+1] == "copy"; got != copied {
+			t.Errorf("Expected copy=%v for %q, got %v", copied, codec, args)
+		}
+		if !copied && args[i+1] != "libx264" {
+			t.Errorf("Expected %q to be re-encoded to H.264, got %v", codec, args)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MediaVideo     = "video"
+	MediaRecording = "recording"
+)
+
+// MissingMedia is a video or recording whose file no longer exists.
+type MissingMedia struct {
+	Kind     string `json:"kind"`
+	ID       string `json:"id"`
+	FilePath string `json:"file_path"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+type RelinkResult struct {
+	Relinked []MissingMedia `json:"relinked"`
+	Missing  []MissingMedia `json:"missing"`
+}
+
+// HashFile returns the hex SHA-256 of a file's contents.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// relativeTo returns path relative to root with forward slashes if it lies
+// inside root, and path unchanged otherwise.
+func relativeTo(root, path string) string {
+	if path == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func resolveFrom(root, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// portable returns a copy of the project with media paths made relative to
+// the project folder, as written to project.json.
+func (p *Project) portable() *Project {
+	saved := *p
+	if p.Video != nil {
+		video := *p.Video
+		video.FilePath = relativeTo(p.Path, video.FilePath)
+		saved.Video = &video
+	}
+	saved.Recordings = make([]*Recording, len(p.Recordings))
+	for i, r := range p.Recordings {
+		recording := *r
+		recording.FilePath = relativeTo(p.Path, recording.FilePath)
+		saved.Recordings[i] = &recording
+	}
+	return &saved
+}
+
+// resolvePaths anchors the project at dir and turns relative media paths
+// back into absolute ones.
+func (p *Project) resolvePaths(dir string) {
+	p.Path = dir
+	if p.Video != nil {
+		p.Video.FilePath = resolveFrom(dir, p.Video.FilePath)
+	}
+	for _, r := range p.Recordings {
+		r.FilePath = resolveFrom(dir, r.FilePath)
+	}
+}
+
+// migrateRelativePaths rewrites absolute media paths inside the project
+// folder as relative ones.
+func migrateRelativePaths(doc map[string]any) error {
+	root, _ := doc["path"].(string)
+	if root == "" {
+		return nil
+	}
+	relativize := func(item map[string]any) {
+		if path, ok := item["file_path"].(string); ok {
+			item["file_path"] = relativeTo(root, path)
+		}
+	}
+	if video, ok := doc["video"].(map[string]any); ok {
+		relativize(video)
+	}
+	recordings, _ := doc["recordings"].([]any)
+	for _, item := range recordings {
+		if r, ok := item.(map[string]any); ok {
+			relativize(r)
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (p *Project) MissingMedia() []MissingMedia {
+	missing := make([]MissingMedia, 0)
+	if p.Video != nil && p.Video.FilePath != "" && !fileExists(p.Video.FilePath) {
+		missing = append(missing, MissingMedia{Kind: MediaVideo, ID: p.Video.ID, FilePath: p.Video.FilePath, Hash: p.Video.Hash})
+	}
+	for _, r := range p.Recordings {
+		if !fileExists(r.FilePath) {
+			missing = append(missing, MissingMedia{Kind: MediaRecording, ID: r.ID, FilePath: r.FilePath, Hash: r.Hash})
+		}
+	}
+	return missing
+}
+
+// FindMissingMedia searches dir for the project's missing media. A file
+// matches by name, confirmed by hash when one is known; media renamed since
+// is found by hash among files of the same type. Relinked entries hold the
+// path each file was found at.
+func (p *Project) FindMissingMedia(dir string) (*RelinkResult, error) {
+	missing := p.MissingMedia()
+	result := &RelinkResult{Relinked: make([]MissingMedia, 0), Missing: make([]MissingMedia, 0)}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	byName := make(map[string][]string)
+	byExt := make(map[string][]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		byName[d.Name()] = append(byName[d.Name()], path)
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		byExt[ext] = append(byExt[ext], path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string)
+	hashOf := func(path string) string {
+		if h, ok := hashes[path]; ok {
+			return h
+		}
+		h, _ := HashFile(path)
+		hashes[path] = h
+		return h
+	}
+
+	for _, m := range missing {
+		found := ""
+		for _, candidate := range byName[filepath.Base(m.FilePath)] {
+			if m.Hash == "" || hashOf(candidate) == m.Hash {
+				found = candidate
+				break
+			}
+		}
+		if found == "" && m.Hash != "" {
+			for _, candidate := range byExt[strings.ToLower(filepath.Ext(m.FilePath))] {
+				if hashOf(candidate) == m.Hash {
+					found = candidate
+					break
+				}
+			}
+		}
+		if found == "" {
+			result.Missing = append(result.Missing, m)
+			continue
+		}
+		m.FilePath = found
+		result.Relinked = append(result.Relinked, m)
+	}
+	return result, nil
+}
+
+// relinkTarget is where found media is copied to inside the project: the
+// video next to project.json, as SelectVideo does, and recordings in the
+// recordings folder under their original name.
+func (p *Project) relinkTarget(m MissingMedia) string {
+	switch m.Kind {
+	case MediaVideo:
+		if p.Video != nil {
+			return filepath.Join(p.Path, filepath.Base(p.Video.FilePath))
+		}
+	case MediaRecording:
+		if r := p.GetRecording(m.ID); r != nil {
+			return filepath.Join(p.Path, "recordings", filepath.Base(r.FilePath))
+		}
+	}
+	return ""
+}
+
+func (p *Project) mediaPath(m MissingMedia) string {
+	switch m.Kind {
+	case MediaVideo:
+		if p.Video != nil {
+			return p.Video.FilePath
+		}
+	case MediaRecording:
+		if r := p.GetRecording(m.ID); r != nil {
+			return r.FilePath
+		}
+	}
+	return ""
+}
+
+func (p *Project) setMediaPath(m MissingMedia, path string) {
+	switch m.Kind {
+	case MediaVideo:
+		if p.Video != nil {
+			p.Video.FilePath = path
+			p.Video.FileName = filepath.Base(path)
+		}
+	case MediaRecording:
+		if r := p.GetRecording(m.ID); r != nil {
+			r.FilePath = path
+		}
+	}
+}
+
+// CopyFile copies src to dst, replacing dst if it exists.
+func CopyFile(src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	dest, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		os.Remove(dst)
+		return err
+	}
+	return dest.Close()
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveStoresRelativePaths(t *testing.T) {
+	oldDir := filepath.Join(t.TempDir(), "old")
+	os.MkdirAll(filepath.Join(oldDir, "recordings"), 0755)
+	p := NewProject("Test", oldDir)
+	p.SetVideo(&Video{ID: "v", FilePath: filepath.Join(oldDir, "clip.mp4")})
+	p.AddRecording(NewRecording("char-1", filepath.Join(oldDir, "recordings", "take.wav"), 0, 1))
+	p.AddRecording(NewRecording("char-1", "/elsewhere/external.wav", 5, 1))
+	if err := p.Save(); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(oldDir, "project.json"))
+	if !strings.Contains(string(data), `"file_path": "recordings/take.wav"`) {
+		t.Errorf("Expected a relative recording path, got %s", data)
+	}
+	if !strings.Contains(string(data), `"file_path": "/elsewhere/external.wav"`) {
+		t.Error("Expected media outside the project to stay absolute")
+	}
+
+	newDir := filepath.Join(t.TempDir(), "new")
+	if err := os.Rename(oldDir, newDir); err != nil {
+		t.Fatalf("Failed to move project: %v", err)
+	}
+	loaded, err := LoadProject(newDir)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	if loaded.Path != newDir {
+		t.Errorf("Expected path %s, got %s", newDir, loaded.Path)
+	}
+	if want := filepath.Join(newDir, "recordings", "take.wav"); loaded.Recordings[0].FilePath != want {
+		t.Errorf("Expected %s, got %s", want, loaded.Recordings[0].FilePath)
+	}
+	if want := filepath.Join(newDir, "clip.mp4"); loaded.Video.FilePath != want {
+		t.Errorf("Expected %s, got %s", want, loaded.Video.FilePath)
+	}
+}
+
+func TestLoadProjectMigratesAbsolutePaths(t *testing.T) {
+	oldDir := "/old/location/project"
+	tmpDir := t.TempDir()
+	legacy := `{"schema_version":1,"id":"p1","title":"Old","path":"` + oldDir + `","characters":[],` +
+		`"recordings":[{"id":"r1","character_id":"c1","file_path":"` + oldDir + `/recordings/a.wav","slot_id":"r1","take":1,"active":true}]}`
+	os.WriteFile(filepath.Join(tmpDir, "project.json"), []byte(legacy), 0644)
+	loaded, err := LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	if want := filepath.Join(tmpDir, "recordings", "a.wav"); loaded.Recordings[0].FilePath != want {
+		t.Errorf("Expected %s, got %s", want, loaded.Recordings[0].FilePath)
+	}
+}
+
+func TestRelinkMedia(t *testing.T) {
+	projectDir := t.TempDir()
+	searchDir := t.TempDir()
+	os.MkdirAll(filepath.Join(searchDir, "nested"), 0755)
+	os.WriteFile(filepath.Join(searchDir, "nested", "take.wav"), []byte("take audio"), 0644)
+	os.WriteFile(filepath.Join(searchDir, "renamed.wav"), []byte("other audio"), 0644)
+	os.WriteFile(filepath.Join(searchDir, "lost.wav"), []byte("wrong contents"), 0644)
+	otherHash, _ := HashFile(filepath.Join(searchDir, "renamed.wav"))
+
+	p := NewProject("Test", projectDir)
+	byName := NewRecording("c", filepath.Join(projectDir, "recordings", "take.wav"), 0, 1)
+	byHash := NewRecording("c", filepath.Join(projectDir, "recordings", "other.wav"), 5, 1)
+	byHash.Hash = otherHash
+	lost := NewRecording("c", filepath.Join(projectDir, "recordings", "lost.wav"), 10, 1)
+	lost.Hash = "deadbeef"
+	p.AddRecording(byName)
+	p.AddRecording(byHash)
+	p.AddRecording(lost)
+
+	if n := len(p.MissingMedia()); n != 3 {
+		t.Fatalf("Expected 3 missing, got %d", n)
+	}
+	result, err := p.FindMissingMedia(searchDir)
+	if err != nil {
+		t.Fatalf("Failed to search for media: %v", err)
+	}
+	if len(result.Relinked) != 2 || len(result.Missing) != 1 {
+		t.Errorf("Expected 2 found and 1 missing, got %+v", result)
+	}
+	if result.Relinked[0].FilePath != filepath.Join(searchDir, "nested", "take.wav") {
+		t.Errorf("Expected a match by name, got %s", result.Relinked[0].FilePath)
+	}
+	if result.Relinked[1].FilePath != filepath.Join(searchDir, "renamed.wav") {
+		t.Errorf("Expected a match by hash, got %s", result.Relinked[1].FilePath)
+	}
+
+	h := NewHistory(p, 0)
+	if err := h.Execute(NewRelinkMediaCommand(result.Relinked)); err != nil {
+		t.Fatalf("Failed to relink: %v", err)
+	}
+	for _, r := range []*Recording{byName, byHash} {
+		if dir := filepath.Dir(r.FilePath); dir != filepath.Join(projectDir, "recordings") {
+			t.Errorf("Expected %s to be copied into the project, got %s", r.ID, r.FilePath)
+		}
+		if !fileExists(r.FilePath) {
+			t.Errorf("Expected %s to exist", r.FilePath)
+		}
+	}
+	if data, _ := os.ReadFile(byHash.FilePath); string(data) != "other audio" {
+		t.Errorf("Expected the renamed file to be copied under its original name, got %q", data)
+	}
+	if n := len(p.MissingMedia()); n != 1 {
+		t.Errorf("Expected 1 missing after relinking, got %d", n)
+	}
+
+	h.Undo()
+	if n := len(p.MissingMedia()); n != 3 {
+		t.Errorf("Expected 3 missing after undo, got %d", n)
+	}
+	if !fileExists(filepath.Join(searchDir, "renamed.wav")) {
+		t.Error("Expected undo to leave the found file in place")
+	}
+	h.Redo()
+	if n := len(p.MissingMedia()); n != 1 {
+		t.Errorf("Expected 1 missing after redo, got %d", n)
+	}
+	if result.Missing[0].ID != lost.ID {
+		t.Error("Expected a name match with the wrong hash to stay missing")
+	}
+}
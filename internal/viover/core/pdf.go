@@ -0,0 +1,308 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A minimal PDF writer for the printed cue sheet: one landscape A4 table
+// set in the standard Helvetica fonts, so nothing has to be embedded.
+
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 8.0
+	pdfLeading    = 10.0
+	pdfCellPad    = 3.0
+)
+
+var cueSheetColumns = []float64{30, 100, 75, 75, 300, 40, 45, 105}
+
+// Glyph widths of printable ASCII, in thousandths of the font size, from
+// the Helvetica and Helvetica-Bold AFM files.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsiExtras maps the punctuation scripts commonly use onto the
+// WinAnsiEncoding code points between 0x80 and 0x9f.
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// ErrPDFText is returned for text the standard PDF fonts cannot show, such
+// as Greek, Cyrillic or CJK. The HTML cue sheet prints any script.
+var ErrPDFText = errors.New("text cannot be printed with the PDF fonts")
+
+func winAnsiByte(r rune) (byte, bool) {
+	switch b, ok := winAnsiExtras[r]; {
+	case ok:
+		return b, true
+	case r == '\t':
+		return ' ', true
+	case r == '\n', r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		return byte(r), true
+	}
+	return 0, false
+}
+
+// checkPDFText returns ErrPDFText if s holds anything pdfText cannot encode.
+func checkPDFText(s string) error {
+	for _, r := range s {
+		if _, ok := winAnsiByte(r); !ok {
+			return fmt.Errorf("%w: %q in %q, export the cue sheet as HTML instead", ErrPDFText, r, s)
+		}
+	}
+	return nil
+}
+
+// pdfText encodes s in WinAnsiEncoding, replacing anything it cannot
+// represent with "?". Text is checked with checkPDFText first, so this only
+// happens for labels the writer makes up itself.
+func pdfText(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := winAnsiByte(r)
+		if !ok {
+			b = '?'
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func textWidth(text []byte, bold bool, size float64) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range text {
+		if b >= 0x20 && b < 0x7f {
+			total += widths[b-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// wrapText breaks encoded text into lines no wider than width, splitting
+// words that do not fit on a line of their own.
+func wrapText(text []byte, bold bool, width float64) [][]byte {
+	lines := make([][]byte, 0)
+	for _, para := range bytes.Split(text, []byte("\n")) {
+		var line []byte
+		for _, word := range bytes.Fields(para) {
+			candidate := word
+			if len(line) > 0 {
+				candidate = append(append(append([]byte{}, line...), ' '), word...)
+			}
+			if textWidth(candidate, bold, pdfFontSize) <= width {
+				line = candidate
+				continue
+			}
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+			line = nil
+			for len(word) > 0 {
+				n := len(word)
+				for n > 1 && textWidth(word[:n], bold, pdfFontSize) > width {
+					n--
+				}
+				if n == len(word) {
+					line = word
+					break
+				}
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func pdfString(text []byte) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range text {
+		if c == '\\' || c == '(' || c == ')' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (pg *pdfPage) text(x, y float64, bold bool, size float64, text []byte) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&pg.content, "BT /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// row draws one table row with its top edge at y and returns its height.
+func (pg *pdfPage) row(y float64, cells [][][]byte, bold bool) float64 {
+	height := pdfRowHeight(cells)
+	if bold {
+		fmt.Fprintf(&pg.content, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, y-height, pdfPageWidth-2*pdfMargin, height)
+	}
+	x := pdfMargin
+	for i, lines := range cells {
+		fmt.Fprintf(&pg.content, "%.2f %.2f %.2f %.2f re S\n", x, y-height, cueSheetColumns[i], height)
+		for j, line := range lines {
+			pg.text(x+pdfCellPad, y-pdfCellPad-pdfFontSize-float64(j)*pdfLeading, bold, pdfFontSize, line)
+		}
+		x += cueSheetColumns[i]
+	}
+	return height
+}
+
+// splitCells cuts a row after its first n lines, for rows too tall to fit
+// on a page.
+func splitCells(cells [][][]byte, n int) (head, rest [][][]byte) {
+	head = make([][][]byte, len(cells))
+	rest = make([][][]byte, len(cells))
+	for i, c := range cells {
+		k := min(n, len(c))
+		head[i], rest[i] = c[:k], c[k:]
+	}
+	return head, rest
+}
+
+func pdfRowHeight(cells [][][]byte) float64 {
+	lines := 1
+	for _, c := range cells {
+		lines = max(lines, len(c))
+	}
+	return float64(lines)*pdfLeading + 2*pdfCellPad
+}
+
+func wrapCells(fields []string, bold bool) [][][]byte {
+	cells := make([][][]byte, len(fields))
+	for i, f := range fields {
+		cells[i] = wrapText(pdfText(f), bold, cueSheetColumns[i]-2*pdfCellPad)
+	}
+	return cells
+}
+
+// writeCueSheetPDF lays the cue sheet out as a table, repeating the header
+// row on every page and numbering the pages in the footer. Rows move to the
+// next page whole unless they are taller than a page, in which case they
+// continue over as many pages as they need.
+func writeCueSheetPDF(w io.Writer, p *Project, rows []CueSheetRow) error {
+	subtitle := cueSheetSubtitle(p)
+	if err := checkPDFText(p.Title); err != nil {
+		return err
+	}
+	if err := checkPDFText(subtitle); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		for _, f := range r.fields() {
+			if err := checkPDFText(f); err != nil {
+				return fmt.Errorf("cue %d: %w", r.Number, err)
+			}
+		}
+	}
+
+	header := wrapCells(cueSheetHeader, true)
+	bottom := pdfMargin + 14
+	fullPage := pdfPageHeight - pdfMargin - pdfRowHeight(header) - bottom
+
+	pages := make([]*pdfPage, 0)
+	var page *pdfPage
+	var y, top float64
+	newPage := func() {
+		page = &pdfPage{}
+		page.content.WriteString("0.5 w\n")
+		pages = append(pages, page)
+		y = pdfPageHeight - pdfMargin
+		if len(pages) == 1 {
+			page.text(pdfMargin, y-14, true, 14, pdfText(p.Title+" - ADR Cue Sheet"))
+			page.text(pdfMargin, y-28, false, 9, pdfText(subtitle))
+			y -= 40
+		}
+		y -= page.row(y, header, true)
+		top = y
+	}
+	newPage()
+	for _, r := range rows {
+		cells := wrapCells(r.fields(), false)
+		if y-pdfRowHeight(cells) < bottom && pdfRowHeight(cells) <= fullPage && y < top {
+			newPage()
+		}
+		for y-pdfRowHeight(cells) < bottom {
+			n := int((y - bottom - 2*pdfCellPad) / pdfLeading)
+			if n < 1 {
+				newPage()
+				continue
+			}
+			head, rest := splitCells(cells, n)
+			page.row(y, head, false)
+			newPage()
+			cells = rest
+		}
+		y -= page.row(y, cells, false)
+	}
+	for i, pg := range pages {
+		label := pdfText(fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+		pg.text(pdfPageWidth-pdfMargin-textWidth(label, false, pdfFontSize), pdfMargin, false, pdfFontSize, label)
+	}
+
+	var buf bytes.Buffer
+	offsets := make([]int, 0, 4+2*len(pages))
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, pg := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", pg.content.Len(), pg.content.String()))
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
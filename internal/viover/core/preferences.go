@@ -0,0 +1,76 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// Preferences are application settings kept across launches. Each field is
+// stored as its own row in the preferences table, keyed by its JSON name.
+type Preferences struct {
+	MicrophoneName string  `json:"microphone_name"`
+	InputGainDB    float64 `json:"input_gain_db"`
+	ExportFormat   string  `json:"export_format"`
+	MasterVolume   float64 `json:"master_volume"`
+	ExportDir      string  `json:"export_dir"`
+	ProjectRoot    string  `json:"project_root"`
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{
+		ExportFormat: "wav",
+		MasterVolume: 1.0,
+	}
+}
+
+// GetPreferences returns the saved preferences over the defaults. Values
+// that no longer decode are logged and left at their default.
+func (s *Store) GetPreferences() (Preferences, error) {
+	prefs := DefaultPreferences()
+	rows, err := s.db.Query(`SELECT key, value FROM preferences`)
+	if err != nil {
+		return prefs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return prefs, err
+		}
+		field, err := json.Marshal(map[string]json.RawMessage{key: json.RawMessage(value)})
+		if err != nil {
+			log.Println("Error reading preference", key+":", err)
+			continue
+		}
+		if err := json.Unmarshal(field, &prefs); err != nil {
+			log.Println("Error reading preference", key+":", err)
+		}
+	}
+	return prefs, rows.Err()
+}
+
+func (s *Store) UpdatePreferences(prefs Preferences) error {
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for key, value := range fields {
+		_, err := tx.Exec(
+			`INSERT INTO preferences (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+			key, string(value),
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
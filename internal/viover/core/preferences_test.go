@@ -0,0 +1,44 @@
+package core
+
+import "testing"
+
+func TestStorePreferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStore(tmpDir)
+	prefs, err := store.GetPreferences()
+	if err != nil {
+		t.Fatalf("Failed to load preferences: %v", err)
+	}
+	if prefs != DefaultPreferences() {
+		t.Errorf("Expected defaults, got %+v", prefs)
+	}
+
+	prefs.MicrophoneName = "USB Interface"
+	prefs.InputGainDB = -3.5
+	prefs.ExportFormat = "flac"
+	prefs.ExportDir = "/tmp/exports"
+	if err := store.UpdatePreferences(prefs); err != nil {
+		t.Fatalf("Failed to save preferences: %v", err)
+	}
+	store.Close()
+
+	store, _ = NewStore(tmpDir)
+	defer store.Close()
+	loaded, _ := store.GetPreferences()
+	if loaded != prefs {
+		t.Errorf("Expected %+v, got %+v", prefs, loaded)
+	}
+}
+
+func TestStorePreferencesIgnoresBadValues(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	defer store.Close()
+	store.db.Exec(`INSERT INTO preferences (key, value) VALUES ('master_volume', '"loud"'), ('retired_setting', '1')`)
+	prefs, err := store.GetPreferences()
+	if err != nil {
+		t.Fatalf("Failed to load preferences: %v", err)
+	}
+	if prefs.MasterVolume != 1.0 {
+		t.Errorf("Expected default master volume, got %f", prefs.MasterVolume)
+	}
+}
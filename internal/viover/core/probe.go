@@ -0,0 +1,110 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type AudioStream struct {
+	Codec      string `json:"codec"`
+	Channels   int    `json:"channels"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		RFrameRate   string `json:"r_frame_rate"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		Channels     int    `json:"channels"`
+		SampleRate   string `json:"sample_rate"`
+		Duration     string `json:"duration"`
+		Disposition  struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// ProbeVideo reads duration, frame rate, resolution and stream information
+// from a media file with ffprobe.
+func ProbeVideo(path string) (*Video, error) {
+	ffprobePath := findFFprobe()
+	cmd := exec.Command(ffprobePath, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe error: %w (ffprobe path: %s)", err, ffprobePath)
+	}
+	return parseProbeOutput(out)
+}
+
+func parseProbeOutput(data []byte) (*Video, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	video := &Video{AudioStreams: make([]AudioStream, 0)}
+	foundVideo := false
+	for _, s := range probe.Streams {
+		switch s.CodecType {
+		case "video":
+			if foundVideo || s.Disposition.AttachedPic == 1 {
+				continue
+			}
+			foundVideo = true
+			video.Codec = s.CodecName
+			video.Width = s.Width
+			video.Height = s.Height
+			rate := s.RFrameRate
+			if num, _, ok := parseRational(rate); !ok || num == 0 {
+				rate = s.AvgFrameRate
+			}
+			if num, den, ok := parseRational(rate); ok && num > 0 {
+				video.FrameRateNum = num
+				video.FrameRateDen = den
+				video.FrameRate = float64(num) / float64(den)
+			}
+			if d, err := strconv.ParseFloat(s.Duration, 64); err == nil {
+				video.Duration = d
+			}
+		case "audio":
+			sampleRate, _ := strconv.Atoi(s.SampleRate)
+			video.AudioStreams = append(video.AudioStreams, AudioStream{
+				Codec:      s.CodecName,
+				Channels:   s.Channels,
+				SampleRate: sampleRate,
+			})
+		}
+	}
+	if !foundVideo {
+		return nil, fmt.Errorf("no video stream found")
+	}
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil && d > 0 {
+		video.Duration = d
+	}
+	return video, nil
+}
+
+func parseRational(s string) (int, int, bool) {
+	numStr, denStr, found := strings.Cut(s, "/")
+	num, err := strconv.Atoi(numStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return num, 1, true
+	}
+	den, err := strconv.Atoi(denStr)
+	if err != nil || den == 0 {
+		return 0, 0, false
+	}
+	return num, den, true
+}
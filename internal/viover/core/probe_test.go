@@ -0,0 +1,53 @@
+package core
+
+import "testing"
+
+const sampleProbeOutput = `{
+	"streams": [
+		{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30000/1001", "avg_frame_rate": "30000/1001", "duration": "61.995267"},
+		{"codec_type": "audio", "codec_name": "aac", "channels": 2, "sample_rate": "48000", "duration": "62.016000"},
+		{"codec_type": "video", "codec_name": "mjpeg", "width": 320, "height": 240, "r_frame_rate": "90000/1", "disposition": {"attached_pic": 1}}
+	],
+	"format": {"duration": "62.016000"}
+}`
+
+func TestParseProbeOutput(t *testing.T) {
+	video, err := parseProbeOutput([]byte(sampleProbeOutput))
+	if err != nil {
+		t.Fatalf("Failed to parse probe output: %v", err)
+	}
+	if video.Codec != "h264" || video.Width != 1920 || video.Height != 1080 {
+		t.Errorf("Unexpected video stream info: %+v", video)
+	}
+	if video.FrameRateNum != 30000 || video.FrameRateDen != 1001 {
+		t.Errorf("Expected 30000/1001 fps, got %d/%d", video.FrameRateNum, video.FrameRateDen)
+	}
+	if video.FrameRate < 29.97 || video.FrameRate > 29.98 {
+		t.Errorf("Expected 29.97 fps, got %f", video.FrameRate)
+	}
+	if video.Duration != 62.016 {
+		t.Errorf("Expected container duration 62.016, got %f", video.Duration)
+	}
+	if !video.HasAudio() || video.AudioStreams[0].SampleRate != 48000 || video.AudioStreams[0].Channels != 2 {
+		t.Errorf("Unexpected audio streams: %+v", video.AudioStreams)
+	}
+}
+
+func TestParseProbeOutputWithoutVideo(t *testing.T) {
+	_, err := parseProbeOutput([]byte(`{"streams": [{"codec_type": "audio", "codec_name": "mp3"}], "format": {}}`))
+	if err == nil {
+		t.Error("Expected error for a file without a video stream")
+	}
+}
+
+func TestProjectTimelineLengthUsesVideo(t *testing.T) {
+	p := NewProject("Test", "/tmp")
+	p.AddRecording(NewRecording("char-1", "/a.wav", 5, 2))
+	if p.TimelineLength() != 7 {
+		t.Errorf("Expected 7 without video, got %f", p.TimelineLength())
+	}
+	p.SetVideo(&Video{Duration: 90})
+	if p.TimelineLength() != 90 {
+		t.Errorf("Expected video duration 90, got %f", p.TimelineLength())
+	}
+}
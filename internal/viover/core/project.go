@@ -1,31 +1,48 @@
 package core
 
 import (
-	"encoding/json"
 	"math"
-	"os"
-	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Project struct {
-	ID         string       `json:"id"`
-	Title      string       `json:"title"`
-	Path       string       `json:"path"`
-	Video      *Video       `json:"video,omitempty"`
-	Characters []*Character `json:"characters"`
-	Recordings []*Recording `json:"recordings"`
-	CreatedAt  time.Time    `json:"created_at"`
-	UpdatedAt  time.Time    `json:"updated_at"`
+	SchemaVersion int          `json:"schema_version"`
+	ID            string       `json:"id"`
+	Title         string       `json:"title"`
+	Path          string       `json:"path"`
+	StartTimecode string       `json:"start_timecode,omitempty"`
+	AudioFormat   AudioFormat  `json:"audio_format"`
+	Video         *Video       `json:"video,omitempty"`
+	Characters    []*Character `json:"characters"`
+	Recordings    []*Recording `json:"recordings"`
+	Cues          []*Cue       `json:"cues"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+
+	lastBackup time.Time
 }
 
 type Video struct {
-	ID        string `json:"id"`
-	FileName  string `json:"file_name"`
-	FilePath  string `json:"file_path"`
-	Thumbnail string `json:"thumbnail"`
+	ID           string        `json:"id"`
+	FileName     string        `json:"file_name"`
+	FilePath     string        `json:"file_path"`
+	Hash         string        `json:"hash,omitempty"`
+	Thumbnail    string        `json:"thumbnail"`
+	Duration     float64       `json:"duration"`
+	FrameRate    float64       `json:"frame_rate"`
+	FrameRateNum int           `json:"frame_rate_num"`
+	FrameRateDen int           `json:"frame_rate_den"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	Codec        string        `json:"codec"`
+	AudioStreams []AudioStream `json:"audio_streams"`
+}
+
+func (v *Video) HasAudio() bool {
+	return len(v.AudioStreams) > 0
 }
 
 type Character struct {
@@ -38,21 +55,32 @@ type Recording struct {
 	ID          string  `json:"id"`
 	CharacterID string  `json:"character_id"`
 	FilePath    string  `json:"file_path"`
+	Hash        string  `json:"hash,omitempty"`
 	Timecode    float64 `json:"timecode"`
 	Duration    float64 `json:"duration"`
 	Volume      float64 `json:"volume"`
 	GainDB      float64 `json:"gain_db"`
+	SlotID      string  `json:"slot_id"`
+	Take        int     `json:"take"`
+	Active      bool    `json:"active"`
 }
 
+// TakeMatchTolerance is how close, in seconds, a new recording must start to
+// an existing one of the same character to be treated as another take of it.
+const TakeMatchTolerance = 0.1
+
 func NewProject(title, path string) *Project {
 	return &Project{
-		ID:         uuid.NewString(),
-		Title:      title,
-		Path:       path,
-		Characters: make([]*Character, 0),
-		Recordings: make([]*Recording, 0),
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		SchemaVersion: ProjectSchemaVersion,
+		ID:            uuid.NewString(),
+		Title:         title,
+		Path:          path,
+		AudioFormat:   DefaultAudioFormat(),
+		Characters:    make([]*Character, 0),
+		Recordings:    make([]*Recording, 0),
+		Cues:          make([]*Cue, 0),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 }
 
@@ -75,6 +103,37 @@ func NewRecording(characterID, filePath string, timecode, duration float64) *Rec
 	}
 }
 
+// LinearGain is the recording's volume and gain combined into one factor.
+// A zero volume is treated as unity, matching recordings saved before the
+// volume control existed.
+func (r *Recording) LinearGain() float64 {
+	volume := r.Volume
+	if volume == 0 {
+		volume = 1.0
+	}
+	return volume * DBToLinear(r.GainDB)
+}
+
+// Format returns the project's audio format, or the default if it is not
+// set to a supported one.
+func (p *Project) Format() AudioFormat {
+	if p.AudioFormat.Validate() != nil {
+		return DefaultAudioFormat()
+	}
+	return p.AudioFormat
+}
+
+// SetAudioFormat changes the format of future takes and exports. Existing
+// takes keep the format they were recorded in and are converted on export.
+func (p *Project) SetAudioFormat(f AudioFormat) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	p.AudioFormat = f
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
 func (p *Project) SetVideo(v *Video) {
 	p.Video = v
 	p.UpdatedAt = time.Now()
@@ -99,9 +158,23 @@ func (p *Project) RemoveCharacter(id string) {
 		}
 	}
 	p.Recordings = newRecordings
+	for _, c := range p.Cues {
+		if c.CharacterID == id {
+			c.CharacterID = ""
+		}
+	}
 	p.UpdatedAt = time.Now()
 }
 
+func (p *Project) GetCharacter(id string) *Character {
+	for _, c := range p.Characters {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
 func (p *Project) UpdateCharacter(id, name, color string) {
 	for _, c := range p.Characters {
 		if c.ID == id {
@@ -113,7 +186,28 @@ func (p *Project) UpdateCharacter(id, name, color string) {
 	p.UpdatedAt = time.Now()
 }
 
+// AddRecording adds r as the newest, active take of the slot it belongs to.
+// Recordings without a slot join an existing slot of the same character at
+// the same position, or start a new one.
 func (p *Project) AddRecording(r *Recording) {
+	if r.SlotID == "" {
+		r.SlotID = r.ID
+		for _, existing := range p.Recordings {
+			if existing.CharacterID == r.CharacterID && math.Abs(existing.Timecode-r.Timecode) <= TakeMatchTolerance {
+				r.SlotID = existing.SlotID
+				r.Timecode = existing.Timecode
+				break
+			}
+		}
+	}
+	r.Take = 1
+	for _, existing := range p.Takes(r.SlotID) {
+		existing.Active = false
+		if existing.Take >= r.Take {
+			r.Take = existing.Take + 1
+		}
+	}
+	r.Active = true
 	p.Recordings = append(p.Recordings, r)
 	p.UpdatedAt = time.Now()
 }
@@ -122,22 +216,85 @@ func (p *Project) RemoveRecording(id string) {
 	for i, r := range p.Recordings {
 		if r.ID == id {
 			p.Recordings = append(p.Recordings[:i], p.Recordings[i+1:]...)
+			if r.Active {
+				if takes := p.Takes(r.SlotID); len(takes) > 0 {
+					takes[len(takes)-1].Active = true
+				}
+			}
 			break
 		}
 	}
 	p.UpdatedAt = time.Now()
 }
 
+// UpdateRecordingTimecode moves a recording and every other take in its slot.
 func (p *Project) UpdateRecordingTimecode(id string, newTimecode float64) {
+	r := p.GetRecording(id)
+	if r == nil {
+		return
+	}
+	delta := newTimecode - r.Timecode
+	for _, take := range p.Takes(r.SlotID) {
+		take.Timecode += delta
+	}
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Project) GetRecording(id string) *Recording {
 	for _, r := range p.Recordings {
 		if r.ID == id {
-			r.Timecode = newTimecode
-			break
+			return r
+		}
+	}
+	return nil
+}
+
+// Takes returns every recording in a slot ordered by take number.
+func (p *Project) Takes(slotID string) []*Recording {
+	takes := make([]*Recording, 0)
+	for _, r := range p.Recordings {
+		if r.SlotID == slotID {
+			takes = append(takes, r)
 		}
 	}
+	sort.Slice(takes, func(i, j int) bool { return takes[i].Take < takes[j].Take })
+	return takes
+}
+
+func (p *Project) SetActiveTake(id string) {
+	r := p.GetRecording(id)
+	if r == nil {
+		return
+	}
+	for _, take := range p.Takes(r.SlotID) {
+		take.Active = take.ID == id
+	}
 	p.UpdatedAt = time.Now()
 }
 
+func (p *Project) ActiveRecordings() []*Recording {
+	recordings := make([]*Recording, 0)
+	for _, r := range p.Recordings {
+		if r.Active {
+			recordings = append(recordings, r)
+		}
+	}
+	return recordings
+}
+
+// TimelineLength is the video's duration, extended to the end of the last
+// active take if one runs past it.
+func (p *Project) TimelineLength() float64 {
+	var length float64
+	if p.Video != nil {
+		length = p.Video.Duration
+	}
+	for _, r := range p.ActiveRecordings() {
+		length = math.Max(length, r.Timecode+r.Duration)
+	}
+	return length
+}
+
 func (p *Project) UpdateRecordingVolume(id string, volume float64) {
 	for _, r := range p.Recordings {
 		if r.ID == id {
@@ -167,25 +324,3 @@ func (p *Project) GetRecordingsForCharacter(characterID string) []*Recording {
 	}
 	return recordings
 }
-
-func (p *Project) Save() error {
-	projectFile := filepath.Join(p.Path, "project.json")
-	data, err := json.MarshalIndent(p, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(projectFile, data, 0644)
-}
-
-func LoadProject(path string) (*Project, error) {
-	projectFile := filepath.Join(path, "project.json")
-	data, err := os.ReadFile(projectFile)
-	if err != nil {
-		return nil, err
-	}
-	var project Project
-	if err := json.Unmarshal(data, &project); err != nil {
-		return nil, err
-	}
-	return &project, nil
-}
@@ -156,3 +156,105 @@ func TestProjectSaveAndLoad(t *testing.T) {
 		t.Errorf("Expected 1 recording, got %d", len(loaded.Recordings))
 	}
 }
+
+func TestLoadProjectRecoversPartialRecording(t *testing.T) {
+	tmpDir := t.TempDir()
+	recordingsDir := filepath.Join(tmpDir, "recordings")
+	os.MkdirAll(recordingsDir, 0755)
+	p := NewProject("Crash Test", tmpDir)
+	if err := p.Save(); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+
+	wavPath := filepath.Join(recordingsDir, "take.wav")
+	w, err := NewWAVWriter(wavPath, 44100, 16, 1)
+	if err != nil {
+		t.Fatalf("Failed to create WAV writer: %v", err)
+	}
+	w.Write(make([]byte, 44100*2))
+	w.file.Close()
+	writePartialMarker(wavPath, partialRecording{ID: "rec-1", CharacterID: "char-1", Timecode: 12.5})
+
+	loaded, err := LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	if len(loaded.Recordings) != 1 {
+		t.Fatalf("Expected 1 recovered recording, got %d", len(loaded.Recordings))
+	}
+	r := loaded.Recordings[0]
+	if r.ID != "rec-1" || r.CharacterID != "char-1" || r.Timecode != 12.5 {
+		t.Errorf("Unexpected recovered recording: %+v", r)
+	}
+	if r.Duration != 1.0 {
+		t.Errorf("Expected duration 1.0, got %f", r.Duration)
+	}
+	if _, err := os.Stat(wavPath + partialSuffix); !os.IsNotExist(err) {
+		t.Error("Expected partial marker to be removed")
+	}
+	peaks, err := GetWaveformPeaks(wavPath, 16)
+	if err != nil {
+		t.Fatalf("Expected recovered WAV to be readable: %v", err)
+	}
+	if len(peaks) != 16 {
+		t.Errorf("Expected 16 peaks, got %d", len(peaks))
+	}
+}
+
+func TestProjectTakes(t *testing.T) {
+	p := NewProject("Test", "/tmp")
+	first := NewRecording("char-1", "/a.wav", 10.0, 2.0)
+	second := NewRecording("char-1", "/b.wav", 10.05, 2.0)
+	other := NewRecording("char-2", "/c.wav", 10.0, 2.0)
+	p.AddRecording(first)
+	p.AddRecording(second)
+	p.AddRecording(other)
+
+	if second.SlotID != first.SlotID {
+		t.Error("Expected second recording to join the first recording's slot")
+	}
+	if other.SlotID == first.SlotID {
+		t.Error("Expected another character's recording to get its own slot")
+	}
+	if first.Take != 1 || second.Take != 2 {
+		t.Errorf("Expected takes 1 and 2, got %d and %d", first.Take, second.Take)
+	}
+	if first.Active || !second.Active {
+		t.Error("Expected the newest take to be active")
+	}
+	if len(p.ActiveRecordings()) != 2 {
+		t.Errorf("Expected 2 active recordings, got %d", len(p.ActiveRecordings()))
+	}
+
+	p.SetActiveTake(first.ID)
+	if !first.Active || second.Active {
+		t.Error("Expected first take to be active after switching")
+	}
+
+	p.UpdateRecordingTimecode(first.ID, 20.0)
+	if second.Timecode != 20.0 {
+		t.Errorf("Expected every take to move with the slot, got %f", second.Timecode)
+	}
+
+	p.RemoveRecording(first.ID)
+	if !second.Active {
+		t.Error("Expected remaining take to become active")
+	}
+	if len(p.Takes(first.SlotID)) != 1 {
+		t.Errorf("Expected 1 take left, got %d", len(p.Takes(first.SlotID)))
+	}
+}
+
+func TestLoadProjectNormalizesLegacyRecordings(t *testing.T) {
+	tmpDir := t.TempDir()
+	legacy := `{"id":"p1","title":"Old","path":"` + tmpDir + `","characters":[],"recordings":[{"id":"r1","character_id":"c1","file_path":"/a.wav","timecode":1,"duration":1,"volume":1,"gain_db":0}]}`
+	os.WriteFile(filepath.Join(tmpDir, "project.json"), []byte(legacy), 0644)
+	loaded, err := LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	r := loaded.Recordings[0]
+	if r.SlotID != "r1" || r.Take != 1 || !r.Active {
+		t.Errorf("Expected legacy recording to become an active single take, got %+v", r)
+	}
+}
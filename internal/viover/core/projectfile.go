@@ -0,0 +1,303 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	projectFileName = "project.json"
+
+	// ProjectSchemaVersion is the project.json schema this build writes.
+	// Files without a schema_version are version 0.
+	ProjectSchemaVersion = 3
+
+	// ProjectBackupCount is how many previous saves are kept as
+	// project.json.1 (newest) to project.json.N (oldest).
+	ProjectBackupCount = 5
+
+	// ProjectBackupInterval is the least time between two backups, so a
+	// burst of saves such as a slider drag keeps older backups around.
+	ProjectBackupInterval = 5 * time.Minute
+)
+
+// ErrNewerProject is returned for projects saved by a newer version of the
+// app, which must not be downgraded or overwritten.
+var ErrNewerProject = errors.New("project was saved by a newer version")
+
+// projectMigrations upgrade a decoded project.json in place. Entry i
+// migrates schema version i to i+1.
+var projectMigrations = []func(doc map[string]any) error{
+	migrateTakes,
+	migrateRelativePaths,
+	migrateAudioFormat,
+}
+
+// migrateTakes turns recordings saved before takes existed into single-take
+// slots.
+func migrateTakes(doc map[string]any) error {
+	recordings, _ := doc["recordings"].([]any)
+	for _, item := range recordings {
+		r, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid recording entry")
+		}
+		if slot, _ := r["slot_id"].(string); slot != "" {
+			continue
+		}
+		r["slot_id"] = r["id"]
+		r["take"] = 1
+		r["active"] = true
+	}
+	return nil
+}
+
+// migrateAudioFormat records the format every take was captured in before
+// it became configurable.
+func migrateAudioFormat(doc map[string]any) error {
+	if _, ok := doc["audio_format"]; ok {
+		return nil
+	}
+	f := DefaultAudioFormat()
+	doc["audio_format"] = map[string]any{
+		"sample_rate": f.SampleRate,
+		"bit_depth":   f.BitDepth,
+		"channels":    f.Channels,
+	}
+	return nil
+}
+
+// migrateProject runs every migration needed to bring data up to
+// ProjectSchemaVersion and reports whether anything changed.
+func migrateProject(data []byte) ([]byte, bool, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	version := 0
+	if v, ok := doc["schema_version"].(float64); ok {
+		version = int(v)
+	}
+	if version > ProjectSchemaVersion {
+		return nil, false, fmt.Errorf("%w: schema version %d, supported %d", ErrNewerProject, version, ProjectSchemaVersion)
+	}
+	if version == ProjectSchemaVersion {
+		return data, false, nil
+	}
+	for v := version; v < ProjectSchemaVersion; v++ {
+		if err := projectMigrations[v](doc); err != nil {
+			return nil, false, fmt.Errorf("migrating project from schema version %d: %w", v, err)
+		}
+	}
+	doc["schema_version"] = ProjectSchemaVersion
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return migrated, true, nil
+}
+
+// Save writes project.json atomically, keeping the previous versions as
+// rotating backups. A backup is taken on the first save after opening the
+// project and then at most once per ProjectBackupInterval. Media inside the
+// project folder is stored with relative paths so the folder can be moved
+// or shared.
+func (p *Project) Save() error {
+	p.SchemaVersion = ProjectSchemaVersion
+	data, err := json.MarshalIndent(p.portable(), "", "  ")
+	if err != nil {
+		return err
+	}
+	projectFile := filepath.Join(p.Path, projectFileName)
+	if time.Since(p.lastBackup) >= ProjectBackupInterval {
+		rotated, err := rotateBackups(projectFile)
+		if err != nil {
+			log.Println("Error rotating project backups:", err)
+		} else if rotated {
+			p.lastBackup = time.Now()
+		}
+	}
+	return writeFileAtomic(projectFile, data, 0644)
+}
+
+// writeFileAtomic writes to a temporary file in the same directory, syncs it
+// and renames it over path, so readers see either the old or the new file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+func backupPath(projectFile string, n int) string {
+	return fmt.Sprintf("%s.%d", projectFile, n)
+}
+
+// rotateBackups shifts project.json.1..N-1 up by one and copies the current
+// project.json to project.json.1. The current file is copied rather than
+// moved so a crash never leaves the project without a project.json. A
+// current file that does not decode is left out, so a corrupt save never
+// pushes a good backup out.
+func rotateBackups(projectFile string) (bool, error) {
+	current, err := os.ReadFile(projectFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := decodeProject(current, filepath.Dir(projectFile)); err != nil {
+		return false, nil
+	}
+	os.Remove(backupPath(projectFile, ProjectBackupCount))
+	for n := ProjectBackupCount - 1; n >= 1; n-- {
+		if err := os.Rename(backupPath(projectFile, n), backupPath(projectFile, n+1)); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	if err := writeFileAtomic(backupPath(projectFile, 1), current, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type ProjectBackup struct {
+	Index     int       `json:"index"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListProjectBackups returns the backups of the project at path,
+// newest first.
+func ListProjectBackups(path string) []ProjectBackup {
+	projectFile := filepath.Join(path, projectFileName)
+	backups := make([]ProjectBackup, 0)
+	for n := 1; n <= ProjectBackupCount; n++ {
+		info, err := os.Stat(backupPath(projectFile, n))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, ProjectBackup{Index: n, UpdatedAt: info.ModTime()})
+	}
+	return backups
+}
+
+// RestoreProjectBackup rolls project.json back to backup n. The replaced
+// project.json becomes the newest backup, so a restore can itself be undone.
+func RestoreProjectBackup(path string, n int) (*Project, error) {
+	projectFile := filepath.Join(path, projectFileName)
+	data, err := os.ReadFile(backupPath(projectFile, n))
+	if err != nil {
+		return nil, err
+	}
+	project, err := decodeProject(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("backup %d is unreadable: %w", n, err)
+	}
+	project.restoreTrashedRecordings()
+	if err := project.Save(); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func decodeProject(data []byte, dir string) (*Project, error) {
+	migrated, _, err := migrateProject(data)
+	if err != nil {
+		return nil, err
+	}
+	var project Project
+	if err := json.Unmarshal(migrated, &project); err != nil {
+		return nil, err
+	}
+	if project.Cues == nil {
+		project.Cues = make([]*Cue, 0)
+	}
+	project.resolvePaths(dir)
+	return &project, nil
+}
+
+// LoadProject reads project.json, upgrading older schema versions and
+// resolving media paths against path, wherever the folder now lives. If the
+// file is corrupt, the newest readable backup is used instead.
+func LoadProject(path string) (*Project, error) {
+	projectFile := filepath.Join(path, projectFileName)
+	data, err := os.ReadFile(projectFile)
+	if err != nil {
+		return nil, err
+	}
+	migrated, changed, err := migrateProject(data)
+	var project Project
+	if err == nil {
+		err = json.Unmarshal(migrated, &project)
+	}
+	if err != nil {
+		if errors.Is(err, ErrNewerProject) {
+			return nil, err
+		}
+		restored, backupErr := loadNewestBackup(path)
+		if backupErr != nil {
+			return nil, err
+		}
+		log.Println("Error reading project.json, loaded backup instead:", err)
+		project, changed = *restored, true
+	}
+	if project.Cues == nil {
+		project.Cues = make([]*Cue, 0)
+	}
+	project.resolvePaths(path)
+	recovered := project.RecoverRecordings()
+	if changed || len(recovered) > 0 {
+		if err := project.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return &project, nil
+}
+
+func loadNewestBackup(path string) (*Project, error) {
+	projectFile := filepath.Join(path, projectFileName)
+	for n := 1; n <= ProjectBackupCount; n++ {
+		data, err := os.ReadFile(backupPath(projectFile, n))
+		if err != nil {
+			continue
+		}
+		if project, err := decodeProject(data, path); err == nil {
+			return project, nil
+		}
+	}
+	return nil, fmt.Errorf("no readable backup for %s", projectFile)
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveRotatesBackups(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Test", tmpDir)
+	for i := 0; i < ProjectBackupCount+3; i++ {
+		p.Title = "Save " + string(rune('A'+i))
+		if err := p.Save(); err != nil {
+			t.Fatalf("Failed to save project: %v", err)
+		}
+		p.lastBackup = p.lastBackup.Add(-ProjectBackupInterval)
+	}
+	backups := ListProjectBackups(tmpDir)
+	if len(backups) != ProjectBackupCount {
+		t.Errorf("Expected %d backups, got %d", ProjectBackupCount, len(backups))
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "project.json.1"))
+	var previous Project
+	json.Unmarshal(data, &previous)
+	if previous.Title != "Save G" {
+		t.Errorf("Expected newest backup 'Save G', got '%s'", previous.Title)
+	}
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, ".project.json.tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("Expected no temporary files, got %v", matches)
+	}
+}
+
+func TestSaveBacksUpOncePerInterval(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("First", tmpDir)
+	p.Save()
+	for i := 0; i < ProjectBackupCount+3; i++ {
+		p.Title = "Drag " + string(rune('A'+i))
+		if err := p.Save(); err != nil {
+			t.Fatalf("Failed to save project: %v", err)
+		}
+	}
+	backups := ListProjectBackups(tmpDir)
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup within the interval, got %d", len(backups))
+	}
+	reopened, _ := LoadProject(tmpDir)
+	reopened.Save()
+	if backups := ListProjectBackups(tmpDir); len(backups) != 2 {
+		t.Errorf("Expected a new backup after reopening, got %d", len(backups))
+	}
+}
+
+func TestLoadProjectMigratesSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	legacy := `{"id":"p1","title":"Old","path":"` + tmpDir + `","characters":[],"recordings":[]}`
+	os.WriteFile(filepath.Join(tmpDir, "project.json"), []byte(legacy), 0644)
+	if _, err := LoadProject(tmpDir); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "project.json"))
+	var saved map[string]any
+	json.Unmarshal(data, &saved)
+	if saved["schema_version"] != float64(ProjectSchemaVersion) {
+		t.Errorf("Expected schema_version %d, got %v", ProjectSchemaVersion, saved["schema_version"])
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "project.json.1")); err != nil {
+		t.Error("Expected the pre-migration file to be kept as a backup")
+	}
+	if cues, ok := saved["cues"].([]any); !ok || len(cues) != 0 {
+		t.Errorf("Expected an empty cues list to be saved, got %v", saved["cues"])
+	}
+	loaded, _ := LoadProject(tmpDir)
+	if loaded.AudioFormat != DefaultAudioFormat() {
+		t.Errorf("Expected legacy project to get the default audio format, got %+v", loaded.AudioFormat)
+	}
+}
+
+func TestLoadProjectRejectsNewerSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	future := `{"schema_version":999,"id":"p1","title":"Future","path":"` + tmpDir + `"}`
+	os.WriteFile(filepath.Join(tmpDir, "project.json"), []byte(future), 0644)
+	if _, err := LoadProject(tmpDir); !errors.Is(err, ErrNewerProject) {
+		t.Errorf("Expected ErrNewerProject, got %v", err)
+	}
+}
+
+func TestLoadProjectFallsBackToBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("Good", tmpDir)
+	p.Save()
+	p.Save()
+	os.WriteFile(filepath.Join(tmpDir, "project.json"), []byte(`{"id": "trunc`), 0644)
+
+	loaded, err := LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("Expected backup to load, got %v", err)
+	}
+	if loaded.Title != "Good" {
+		t.Errorf("Expected title 'Good', got '%s'", loaded.Title)
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "project.json.1"))
+	if _, err := decodeProject(data, tmpDir); err != nil {
+		t.Errorf("Expected the corrupt file to stay out of the backups, got %v", err)
+	}
+}
+
+func TestRestoreProjectBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := NewProject("First", tmpDir)
+	p.Save()
+	p.Title = "Second"
+	p.Save()
+
+	restored, err := RestoreProjectBackup(tmpDir, 1)
+	if err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if restored.Title != "First" {
+		t.Errorf("Expected title 'First', got '%s'", restored.Title)
+	}
+	loaded, _ := LoadProject(tmpDir)
+	if loaded.Title != "First" {
+		t.Errorf("Expected restored title saved, got '%s'", loaded.Title)
+	}
+	if _, err := RestoreProjectBackup(tmpDir, ProjectBackupCount+1); err == nil {
+		t.Error("Expected error for missing backup")
+	}
+}
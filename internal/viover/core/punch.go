@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Punch describes a punch-in take: capture runs through PreRoll seconds of
+// lead-in and PostRoll seconds after the out-point, so the actor hears the
+// video around the line, but only the span between In and Out is kept.
+type Punch struct {
+	In       float64
+	Out      float64
+	PreRoll  float64
+	PostRoll float64
+}
+
+func (p Punch) Validate() error {
+	if p.Out <= p.In {
+		return fmt.Errorf("punch out-point must be after in-point")
+	}
+	if p.In < 0 || p.PreRoll < 0 || p.PostRoll < 0 {
+		return fmt.Errorf("punch times must not be negative")
+	}
+	return nil
+}
+
+// frames returns the capture frame offsets of the in and out points,
+// counted from the start of the pre-roll.
+func (p Punch) frames(sampleRate int) (int64, int64) {
+	in := int64(math.Round(p.PreRoll * float64(sampleRate)))
+	out := in + int64(math.Round((p.Out-p.In)*float64(sampleRate)))
+	return in, out
+}
+
+// endFrame is the capture frame offset of the end of the post-roll.
+func (p Punch) endFrame(sampleRate int) int64 {
+	_, out := p.frames(sampleRate)
+	return out + int64(math.Round(p.PostRoll*float64(sampleRate)))
+}
+
+type PunchCountdownEvent struct {
+	Remaining int `json:"remaining"`
+}
+
+func (PunchCountdownEvent) EventName() string { return "punch-countdown" }
+
+type PunchInEvent struct {
+	Timecode float64 `json:"timecode"`
+}
+
+func (PunchInEvent) EventName() string { return "punch-in" }
+
+type PunchOutEvent struct {
+	Timecode float64 `json:"timecode"`
+}
+
+func (PunchOutEvent) EventName() string { return "punch-out" }
+
+type PunchFinishedEvent struct {
+	RecordingID string `json:"recording_id"`
+}
+
+func (PunchFinishedEvent) EventName() string { return "punch-finished" }
+
+const punchPollInterval = 5 * time.Millisecond
+
+// watchPunch follows the capture clock, announcing the countdown and the
+// in and out points, and stops the take once the post-roll has run out.
+func (m *Microphone) watchPunch(punch Punch, sampleRate int, captured *atomic.Int64, done <-chan struct{}) {
+	inFrame, outFrame := punch.frames(sampleRate)
+	endFrame := punch.endFrame(sampleRate)
+	ticker := time.NewTicker(punchPollInterval)
+	defer ticker.Stop()
+	lastRemaining := -1
+	punchedIn, punchedOut := false, false
+	for {
+		frame := captured.Load()
+		if !punchedIn {
+			remaining := int(math.Ceil(float64(inFrame-frame) / float64(sampleRate)))
+			if remaining > 0 && remaining != lastRemaining {
+				m.events.Emit(PunchCountdownEvent{Remaining: remaining})
+				lastRemaining = remaining
+			}
+			if frame >= inFrame {
+				m.events.Emit(PunchInEvent{Timecode: punch.In})
+				punchedIn = true
+			}
+		}
+		if frame >= outFrame && !punchedOut {
+			m.events.Emit(PunchOutEvent{Timecode: punch.Out})
+			punchedOut = true
+		}
+		if frame >= endFrame {
+			m.StopRecording()
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
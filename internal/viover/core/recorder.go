@@ -0,0 +1,150 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	partialSuffix       = ".partial"
+	headerFlushInterval = time.Second
+	ringBufferSeconds   = 10
+)
+
+// takeWriter drains captured PCM from a ring buffer into a WAVWriter on its
+// own goroutine so the capture callback never touches the disk.
+type takeWriter struct {
+	wav      *WAVWriter
+	ring     *ringBuffer
+	done     chan struct{}
+	finished chan error
+}
+
+func newTakeWriter(wav *WAVWriter, format AudioFormat) *takeWriter {
+	t := &takeWriter{
+		wav:      wav,
+		ring:     newRingBuffer(format.SampleRate*format.BytesPerFrame()*ringBufferSeconds, format.BytesPerFrame()),
+		done:     make(chan struct{}),
+		finished: make(chan error, 1),
+	}
+	go t.run()
+	return t
+}
+
+func (t *takeWriter) Push(p []byte) {
+	t.ring.Write(p)
+}
+
+func (t *takeWriter) run() {
+	ticker := time.NewTicker(headerFlushInterval)
+	defer ticker.Stop()
+	chunk := make([]byte, 64*1024)
+	var writeErr error
+	drain := func() {
+		for {
+			n := t.ring.Read(chunk)
+			if n == 0 {
+				return
+			}
+			if _, err := t.wav.Write(chunk[:n]); err != nil && writeErr == nil {
+				writeErr = err
+			}
+		}
+	}
+	for {
+		select {
+		case <-t.ring.ready:
+			drain()
+		case <-ticker.C:
+			drain()
+			if err := t.wav.Flush(); err != nil && writeErr == nil {
+				writeErr = err
+			}
+		case <-t.done:
+			drain()
+			if err := t.wav.Close(); err != nil && writeErr == nil {
+				writeErr = err
+			}
+			if dropped := t.ring.Dropped(); dropped > 0 {
+				log.Printf("recording dropped %d frames: writer could not keep up", dropped)
+			}
+			t.finished <- writeErr
+			return
+		}
+	}
+}
+
+// Close flushes everything still buffered, finalizes the WAV header and
+// waits for the writer goroutine to exit.
+func (t *takeWriter) Close() error {
+	close(t.done)
+	return <-t.finished
+}
+
+// partialRecording is written next to a WAV while it is being captured and
+// removed once the take is finalized. Leftover files mark takes interrupted
+// by a crash.
+type partialRecording struct {
+	ID          string  `json:"id"`
+	CharacterID string  `json:"character_id"`
+	Timecode    float64 `json:"timecode"`
+}
+
+func writePartialMarker(wavPath string, r partialRecording) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(wavPath+partialSuffix, data, 0644)
+}
+
+func removePartialMarker(wavPath string) {
+	os.Remove(wavPath + partialSuffix)
+}
+
+// RecoverRecordings repairs takes that were interrupted before they could be
+// finalized and adds them to the project. It returns the recovered recordings.
+func (p *Project) RecoverRecordings() []*Recording {
+	markers, err := filepath.Glob(filepath.Join(p.Path, "recordings", "*"+partialSuffix))
+	if err != nil {
+		return nil
+	}
+	recovered := make([]*Recording, 0)
+	for _, marker := range markers {
+		wavPath := strings.TrimSuffix(marker, partialSuffix)
+		data, err := os.ReadFile(marker)
+		if err != nil {
+			continue
+		}
+		var partial partialRecording
+		if err := json.Unmarshal(data, &partial); err != nil {
+			log.Println("Error reading partial recording marker:", err)
+			continue
+		}
+		duration, err := RepairWAV(wavPath)
+		if err != nil {
+			log.Println("Error recovering recording:", err)
+			os.Remove(marker)
+			continue
+		}
+		os.Remove(marker)
+		if p.GetRecording(partial.ID) != nil {
+			continue
+		}
+		r := &Recording{
+			ID:          partial.ID,
+			CharacterID: partial.CharacterID,
+			FilePath:    wavPath,
+			Timecode:    partial.Timecode,
+			Duration:    duration,
+			Volume:      1.0,
+		}
+		p.AddRecording(r)
+		recovered = append(recovered, r)
+	}
+	return recovered
+}
@@ -0,0 +1,66 @@
+package core
+
+import "sync"
+
+// ringBuffer hands PCM bytes from the capture callback to the writer
+// goroutine without allocating. Writes never block; frames that do not fit
+// are dropped whole and counted, so the take stays frame aligned.
+type ringBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	frameSize int
+	start     int
+	size      int
+	dropped   int64
+	ready     chan struct{}
+}
+
+// newRingBuffer holds capacity bytes, rounded down to whole frames of
+// frameSize bytes.
+func newRingBuffer(capacity, frameSize int) *ringBuffer {
+	frameSize = max(frameSize, 1)
+	return &ringBuffer{
+		buf:       make([]byte, max(capacity-capacity%frameSize, frameSize)),
+		frameSize: frameSize,
+		ready:     make(chan struct{}, 1),
+	}
+}
+
+func (r *ringBuffer) Write(p []byte) int {
+	r.mu.Lock()
+	free := len(r.buf) - r.size
+	n := len(p)
+	if n > free {
+		n = free - free%r.frameSize
+		r.dropped += int64((len(p) - n) / r.frameSize)
+	}
+	end := (r.start + r.size) % len(r.buf)
+	copied := copy(r.buf[end:], p[:n])
+	copy(r.buf, p[copied:n])
+	r.size += n
+	r.mu.Unlock()
+
+	select {
+	case r.ready <- struct{}{}:
+	default:
+	}
+	return n
+}
+
+func (r *ringBuffer) Read(p []byte) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := min(len(p), r.size)
+	copied := copy(p[:n], r.buf[r.start:])
+	copy(p[copied:n], r.buf)
+	r.start = (r.start + n) % len(r.buf)
+	r.size -= n
+	return n
+}
+
+// Dropped returns how many frames did not fit in the buffer.
+func (r *ringBuffer) Dropped() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
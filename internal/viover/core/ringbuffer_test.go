@@ -0,0 +1,30 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRingBufferDropsWholeFrames(t *testing.T) {
+	r := newRingBuffer(16, 6)
+	if len(r.buf) != 12 {
+		t.Fatalf("Expected capacity rounded down to 12 bytes, got %d", len(r.buf))
+	}
+	first := []byte{1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2}
+	r.Write(first[:6])
+	out := make([]byte, 4)
+	r.Read(out)
+
+	if n := r.Write(first); n != 6 {
+		t.Errorf("Expected one whole frame written, got %d bytes", n)
+	}
+	if d := r.Dropped(); d != 1 {
+		t.Errorf("Expected 1 dropped frame, got %d", d)
+	}
+	rest := make([]byte, 12)
+	n := r.Read(rest)
+	want := []byte{1, 1, 1, 1, 1, 1, 1, 1}
+	if !bytes.Equal(rest[:n], want) {
+		t.Errorf("Expected %v, got %v", want, rest[:n])
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	ScriptFountain = "fountain"
+	ScriptPlain    = "plain"
+)
+
+var (
+	fountainBoneyard = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	fountainNote     = regexp.MustCompile(`(?s)\[\[.*?\]\]`)
+	fountainEmphasis = regexp.MustCompile(`\\?[*_]`)
+	fountainExt      = regexp.MustCompile(`\([^)]*\)`)
+	sceneHeading     = regexp.MustCompile(`(?i)^(INT|EXT|EST|INT\.?/EXT|I/E)[. ]`)
+	plainDialogue    = regexp.MustCompile(`^(\p{L}[\p{L}\p{N} .'\-]{0,30}?)\s*(\([^)]*\))?\s*:\s*(.*)$`)
+)
+
+// fountainTitleKeys are the keys that open a Fountain title page.
+var fountainTitleKeys = map[string]bool{
+	"title": true, "credit": true, "author": true, "authors": true, "source": true,
+	"draft date": true, "date": true, "contact": true, "copyright": true, "notes": true, "revision": true,
+}
+
+// ScriptFormat picks the parser for a screenplay: Fountain for .fountain
+// files, and for anything else plain "CHARACTER: line" when most lines
+// look like that.
+func ScriptFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".fountain", ".spmd":
+		return ScriptFountain
+	}
+	lines, matches := 0, 0
+	for _, line := range strings.Split(normalizeNewlines(string(data)), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		lines++
+		if plainDialogue.MatchString(line) {
+			matches++
+		}
+	}
+	if lines > 0 && matches*2 >= lines {
+		return ScriptPlain
+	}
+	return ScriptFountain
+}
+
+// ParseScriptFile reads a Fountain or plain-text screenplay into unplaced
+// cues, one per line of dialogue.
+func ParseScriptFile(path string) ([]*Cue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cues, err := ParseScript(data, ScriptFormat(path, data))
+	if err != nil {
+		return nil, err
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no dialogue found in %s", filepath.Base(path))
+	}
+	return cues, nil
+}
+
+func ParseScript(data []byte, format string) ([]*Cue, error) {
+	text := normalizeNewlines(string(data))
+	switch format {
+	case ScriptFountain:
+		return parseFountain(text), nil
+	case ScriptPlain:
+		return parsePlainScript(text), nil
+	default:
+		return nil, fmt.Errorf("unsupported script format: %s", format)
+	}
+}
+
+func normalizeNewlines(text string) string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+func parseFountain(text string) []*Cue {
+	text = fountainBoneyard.ReplaceAllString(text, "")
+	text = fountainNote.ReplaceAllString(text, "")
+	lines := strings.Split(text, "\n")
+	blank := func(i int) bool { return i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == "" }
+
+	i := 0
+	if key, _, ok := strings.Cut(strings.TrimSpace(lines[0]), ":"); ok && fountainTitleKeys[strings.ToLower(key)] {
+		for i < len(lines) && !blank(i) {
+			i++
+		}
+	}
+
+	cues := make([]*Cue, 0)
+	for ; i < len(lines); i++ {
+		name, ok := fountainCharacter(strings.TrimSpace(lines[i]))
+		if !ok || !blank(i-1) || blank(i+1) {
+			continue
+		}
+		parts := make([]string, 0)
+		for i++; !blank(i); i++ {
+			line := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(line, "(") && strings.HasSuffix(line, ")") {
+				continue
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "~"))
+			parts = append(parts, fountainEmphasis.ReplaceAllStringFunc(line, func(m string) string {
+				if strings.HasPrefix(m, `\`) {
+					return m[1:]
+				}
+				return ""
+			}))
+		}
+		if len(parts) > 0 {
+			cues = append(cues, NewUnplacedCue(name, strings.Join(parts, "\n")))
+		}
+	}
+	return cues
+}
+
+// fountainCharacter reports whether line is a character cue and returns the
+// name without extensions such as (V.O.) or the dual-dialogue caret.
+func fountainCharacter(line string) (string, bool) {
+	forced := strings.HasPrefix(line, "@")
+	if forced {
+		line = line[1:]
+	} else {
+		if line == "" || strings.ContainsAny(line[:1], "!.>#=~[") || sceneHeading.MatchString(line) ||
+			strings.HasSuffix(line, ":") {
+			return "", false
+		}
+	}
+	name := strings.TrimSpace(fountainExt.ReplaceAllString(strings.TrimSuffix(line, "^"), ""))
+	if name == "" {
+		return "", false
+	}
+	if !forced && (name != strings.ToUpper(name) || strings.IndexFunc(name, unicode.IsLetter) < 0) {
+		return "", false
+	}
+	return name, true
+}
+
+// parsePlainScript reads "CHARACTER: line" scripts. Lines that follow a
+// line of dialogue without a blank line continue it; anything else is
+// treated as direction and skipped.
+func parsePlainScript(text string) []*Cue {
+	type line struct {
+		speaker string
+		parts   []string
+	}
+	lines := make([]*line, 0)
+	var current *line
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			current = nil
+			continue
+		}
+		if m := plainDialogue.FindStringSubmatch(l); m != nil {
+			current = &line{speaker: strings.TrimSpace(m[1])}
+			if m[3] != "" {
+				current.parts = append(current.parts, m[3])
+			}
+			lines = append(lines, current)
+			continue
+		}
+		if current != nil && !strings.HasPrefix(l, "(") && !strings.HasPrefix(l, "[") {
+			current.parts = append(current.parts, l)
+		}
+	}
+	cues := make([]*Cue, 0, len(lines))
+	for _, l := range lines {
+		if len(l.parts) > 0 {
+			cues = append(cues, NewUnplacedCue(l.speaker, strings.Join(l.parts, "\n")))
+		}
+	}
+	return cues
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testFountain = `Title: The Heist
+Author: Someone
+
+INT. VAULT - NIGHT
+
+ALICE stands by the door.
+
+ALICE
+(whispering)
+Is it *open*?
+
+BOB (V.O.)
+Not yet.
+Give me a \*second\*.
+
+/* BOB
+Cut line. */
+
+CUT TO:
+
+@McCLANE
+Yippee-ki-yay.
+
+ALICE ^
+[[alt take]]~Hush now
+`
+
+func TestParseFountain(t *testing.T) {
+	cues, err := ParseScript([]byte(testFountain), ScriptFountain)
+	if err != nil {
+		t.Fatalf("Failed to parse Fountain: %v", err)
+	}
+	want := []struct{ speaker, text string }{
+		{"ALICE", "Is it open?"},
+		{"BOB", "Not yet.\nGive me a *second*."},
+		{"McCLANE", "Yippee-ki-yay."},
+		{"ALICE", "Hush now"},
+	}
+	if len(cues) != len(want) {
+		t.Fatalf("Expected %d cues, got %d: %+v", len(want), len(cues), cues)
+	}
+	for i, w := range want {
+		if cues[i].Speaker != w.speaker || cues[i].Text != w.text {
+			t.Errorf("Expected cue %d to be %s: %q, got %s: %q", i, w.speaker, w.text, cues[i].Speaker, cues[i].Text)
+		}
+		if !cues[i].Unplaced {
+			t.Errorf("Expected cue %d to be unplaced", i)
+		}
+	}
+	if cues[1].Start != 0 || cues[1].End != 2.4 {
+		t.Errorf("Expected a six word line to last 2.4s, got %v-%v", cues[1].Start, cues[1].End)
+	}
+}
+
+func TestParsePlainScript(t *testing.T) {
+	data := "ALICE: Hello there.\nHow are you?\n\n[Bob shrugs]\nBob (off): Fine.\n(beat)\nNARRATOR:\n\nEve: Bye"
+	if got := ScriptFormat("script.txt", []byte(data)); got != ScriptPlain {
+		t.Fatalf("Expected plain format, got %s", got)
+	}
+	cues, err := ParseScript([]byte(data), ScriptPlain)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+	if len(cues) != 3 {
+		t.Fatalf("Expected 3 cues, got %d: %+v", len(cues), cues)
+	}
+	if cues[0].Speaker != "ALICE" || cues[0].Text != "Hello there.\nHow are you?" {
+		t.Errorf("Unexpected first cue: %+v", cues[0])
+	}
+	if cues[1].Speaker != "Bob" || cues[1].Text != "Fine." {
+		t.Errorf("Unexpected second cue: %+v", cues[1])
+	}
+	if cues[2].Speaker != "Eve" {
+		t.Errorf("Expected the empty NARRATOR line to be skipped, got %+v", cues[2])
+	}
+}
+
+func TestScriptFormat(t *testing.T) {
+	if got := ScriptFormat("heist.fountain", []byte("A: b")); got != ScriptFountain {
+		t.Errorf("Expected fountain by extension, got %s", got)
+	}
+	if got := ScriptFormat("heist.txt", []byte(testFountain)); got != ScriptFountain {
+		t.Errorf("Expected fountain for screenplay text, got %s", got)
+	}
+}
+
+func TestParseScriptFileWithoutDialogue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.fountain")
+	os.WriteFile(path, []byte("INT. ROOM - DAY\n\nNothing happens.\n"), 0644)
+	if _, err := ParseScriptFile(path); err == nil {
+		t.Error("Expected an error for a script without dialogue")
+	}
+}
@@ -2,10 +2,13 @@ package core
 
 import (
 	"database/sql"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/edlingao/viover/data"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -15,10 +18,16 @@ type ProjectMeta struct {
 	Path      string `json:"path"`
 	CreatedAt string `json:"created_at,omitempty"`
 	UpdatedAt string `json:"updated_at,omitempty"`
+	Status    string `json:"status,omitempty"`
 }
 
 type Store struct {
 	db *sql.DB
+
+	// statuses caches the health found by the last CheckProjects, so
+	// listing projects does not read every project.json.
+	statusMu sync.Mutex
+	statuses map[string]string
 }
 
 func NewStore(configDir string) (*Store, error) {
@@ -32,7 +41,7 @@ func NewStore(configDir string) (*Store, error) {
 		return nil, err
 	}
 
-	store := &Store{db: db}
+	store := &Store{db: db, statuses: make(map[string]string)}
 	if err := store.migrate(); err != nil {
 		db.Close()
 		return nil, err
@@ -42,21 +51,11 @@ func NewStore(configDir string) (*Store, error) {
 }
 
 func (s *Store) migrate() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS projects (
-		id TEXT PRIMARY KEY,
-		title TEXT NOT NULL,
-		path TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_projects_title ON projects(title);
-	CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects(created_at);
-	`
-
-	_, err := s.db.Exec(schema)
-	return err
+	migrations, err := fs.Sub(data.Migrations, "migrations")
+	if err != nil {
+		return err
+	}
+	return migrateDB(s.db, migrations)
 }
 
 func (s *Store) AddProject(p *Project) error {
@@ -65,11 +64,13 @@ func (s *Store) AddProject(p *Project) error {
 		`INSERT INTO projects (id, title, path, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
 		p.ID, p.Title, p.Path, now, now,
 	)
+	s.setStatus(p.ID, "")
 	return err
 }
 
 func (s *Store) RemoveProject(id string) error {
 	_, err := s.db.Exec(`DELETE FROM projects WHERE id = ?`, id)
+	s.setStatus(id, "")
 	return err
 }
 
@@ -82,6 +83,29 @@ func (s *Store) UpdateProject(id, title string) error {
 	return err
 }
 
+// setStatus records a project's health; an empty status forgets it until
+// the next CheckProjects.
+func (s *Store) setStatus(id, status string) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if status == "" {
+		delete(s.statuses, id)
+	} else {
+		s.statuses[id] = status
+	}
+}
+
+// UpdateProjectPath points a project at the folder it was moved to.
+func (s *Store) UpdateProjectPath(id, path string) error {
+	now := time.Now().Format(time.RFC3339)
+	_, err := s.db.Exec(
+		`UPDATE projects SET path = ?, updated_at = ? WHERE id = ?`,
+		path, now, id,
+	)
+	s.setStatus(id, "")
+	return err
+}
+
 func (s *Store) GetProject(id string) *ProjectMeta {
 	row := s.db.QueryRow(`SELECT id, title, path, created_at, updated_at FROM projects WHERE id = ?`, id)
 	var p ProjectMeta
@@ -98,6 +122,8 @@ func (s *Store) GetProject(id string) *ProjectMeta {
 	return &p
 }
 
+// ListProjects returns the registered projects with the health found by the
+// last CheckProjects.
 func (s *Store) ListProjects() []ProjectMeta {
 	rows, err := s.db.Query(`SELECT id, title, path, created_at, updated_at FROM projects ORDER BY updated_at DESC`)
 	if err != nil {
@@ -120,6 +146,11 @@ func (s *Store) ListProjects() []ProjectMeta {
 		}
 		projects = append(projects, p)
 	}
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	for i := range projects {
+		projects[i].Status = s.statuses[projects[i].ID]
+	}
 	return projects
 }
 
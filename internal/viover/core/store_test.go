@@ -1,6 +1,8 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -122,3 +124,133 @@ func TestStorePersistence(t *testing.T) {
 		t.Errorf("Expected title 'Persist', got '%s'", projects[0].Title)
 	}
 }
+
+func TestStoreUpdateProjectPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStore(tmpDir)
+	defer store.Close()
+	p := NewProject("Moved", "/tmp/old")
+	store.AddProject(p)
+	if err := store.UpdateProjectPath(p.ID, "/tmp/new"); err != nil {
+		t.Fatalf("Failed to update path: %v", err)
+	}
+	if found := store.GetProject(p.ID); found.Path != "/tmp/new" {
+		t.Errorf("Expected path '/tmp/new', got '%s'", found.Path)
+	}
+}
+
+func TestStoreCheckProjects(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	defer store.Close()
+
+	healthy := NewProject("Healthy", t.TempDir())
+	healthy.Save()
+	store.AddProject(healthy)
+	healthy.Title = "Renamed Elsewhere"
+	healthy.Save()
+
+	missing := NewProject("Missing", filepath.Join(t.TempDir(), "gone"))
+	store.AddProject(missing)
+
+	corruptDir := t.TempDir()
+	corrupt := NewProject("Corrupt", corruptDir)
+	store.AddProject(corrupt)
+	os.WriteFile(filepath.Join(corruptDir, "project.json"), []byte("{"), 0644)
+
+	movedDir := t.TempDir()
+	moved := NewProject("Moved", movedDir)
+	store.AddProject(moved)
+	NewProject("Other", movedDir).Save()
+
+	if listed := store.ListProjects(); listed[0].Status != "" {
+		t.Errorf("Expected no status before a check, got '%s'", listed[0].Status)
+	}
+	checked, err := store.CheckProjects()
+	if err != nil {
+		t.Fatalf("Failed to check projects: %v", err)
+	}
+	statuses := make(map[string]ProjectMeta)
+	for _, meta := range checked {
+		statuses[meta.ID] = meta
+	}
+	for _, meta := range store.ListProjects() {
+		if meta.Status != statuses[meta.ID].Status {
+			t.Errorf("Expected listing to report status '%s', got '%s'", statuses[meta.ID].Status, meta.Status)
+		}
+	}
+	if statuses[healthy.ID].Status != ProjectOK || statuses[healthy.ID].Title != "Renamed Elsewhere" {
+		t.Errorf("Expected healthy project reconciled, got %+v", statuses[healthy.ID])
+	}
+	if stored := store.GetProject(healthy.ID); stored.Title != "Renamed Elsewhere" {
+		t.Errorf("Expected reconciled title stored, got '%s'", stored.Title)
+	}
+	if statuses[missing.ID].Status != ProjectMissing {
+		t.Errorf("Expected missing, got '%s'", statuses[missing.ID].Status)
+	}
+	if statuses[corrupt.ID].Status != ProjectCorrupt {
+		t.Errorf("Expected corrupt, got '%s'", statuses[corrupt.ID].Status)
+	}
+	if statuses[moved.ID].Status != ProjectMoved {
+		t.Errorf("Expected moved, got '%s'", statuses[moved.ID].Status)
+	}
+}
+
+func TestStoreRenameProject(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	defer store.Close()
+
+	ok := NewProject("Ok", t.TempDir())
+	ok.Save()
+	store.AddProject(ok)
+	moved := NewProject("Moved", t.TempDir())
+	other := NewProject("Other", moved.Path)
+	other.Save()
+	store.AddProject(moved)
+
+	if err := store.RenameProject(*store.GetProject(ok.ID), "Renamed"); err != nil {
+		t.Fatalf("Failed to rename project: %v", err)
+	}
+	if p, _ := readProjectFile(ok.Path); p.Title != "Renamed" {
+		t.Errorf("Expected project.json title 'Renamed', got '%s'", p.Title)
+	}
+
+	if err := store.RenameProject(*store.GetProject(moved.ID), "Renamed"); err != nil {
+		t.Fatalf("Failed to rename moved project: %v", err)
+	}
+	if p, _ := readProjectFile(moved.Path); p.Title != "Other" {
+		t.Errorf("Expected the other project's title to be kept, got '%s'", p.Title)
+	}
+	if meta := store.GetProject(moved.ID); meta.Title != "Renamed" {
+		t.Errorf("Expected store title 'Renamed', got '%s'", meta.Title)
+	}
+}
+
+func TestStoreRediscoverProjects(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	defer store.Close()
+	root := t.TempDir()
+
+	moved := NewProject("Moved", filepath.Join(t.TempDir(), "old"))
+	store.AddProject(moved)
+	moved.Path = filepath.Join(root, "clients", "Moved")
+	os.MkdirAll(moved.Path, 0755)
+	moved.Save()
+
+	unknown := NewProject("Unknown", filepath.Join(root, "Unknown"))
+	os.MkdirAll(unknown.Path, 0755)
+	unknown.Save()
+
+	found, err := store.RediscoverProjects(root)
+	if err != nil {
+		t.Fatalf("Failed to rediscover: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("Expected 2 projects, got %d", len(found))
+	}
+	if meta := store.GetProject(moved.ID); meta.Path != moved.Path {
+		t.Errorf("Expected path %s, got %s", moved.Path, meta.Path)
+	}
+	if store.GetProject(unknown.ID) == nil {
+		t.Error("Expected unknown project to be registered")
+	}
+}
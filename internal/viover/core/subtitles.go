@@ -0,0 +1,254 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	SubtitleSRT = "srt"
+	SubtitleVTT = "vtt"
+	SubtitleASS = "ass"
+)
+
+var (
+	markupTag     = regexp.MustCompile(`<[^>]*>`)
+	assOverride   = regexp.MustCompile(`\{[^}]*\}`)
+	vttVoice      = regexp.MustCompile(`<v(?:\.[^ >]*)?\s+([^>]+)>`)
+	speakerPrefix = regexp.MustCompile(`^-?\s*(\p{Lu}[\p{Lu}\p{N} .'\-]{0,30}):\s+`)
+	blankLine     = regexp.MustCompile(`\n\s*\n`)
+)
+
+// SubtitleFormat picks the parser for a file from its extension, falling
+// back to sniffing the contents.
+func SubtitleFormat(path string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return SubtitleSRT, nil
+	case ".vtt":
+		return SubtitleVTT, nil
+	case ".ass", ".ssa":
+		return SubtitleASS, nil
+	}
+	head := bytes.TrimPrefix(data, []byte("\ufeff"))
+	switch {
+	case bytes.HasPrefix(head, []byte("WEBVTT")):
+		return SubtitleVTT, nil
+	case bytes.Contains(head, []byte("[Script Info]")):
+		return SubtitleASS, nil
+	case bytes.Contains(head, []byte("-->")):
+		return SubtitleSRT, nil
+	}
+	return "", fmt.Errorf("unsupported subtitle file: %s", filepath.Base(path))
+}
+
+// ParseSubtitleFile reads an SRT, WebVTT or ASS/SSA file into cues.
+func ParseSubtitleFile(path string) ([]*Cue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := SubtitleFormat(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSubtitles(data, format)
+}
+
+// ParseSubtitles parses subtitle data in the given format. Speakers come
+// from ASS actor fields, WebVTT voice tags or an upper-case "NAME:" prefix.
+func ParseSubtitles(data []byte, format string) ([]*Cue, error) {
+	text := normalizeNewlines(string(data))
+	switch format {
+	case SubtitleSRT:
+		return parseSRT(text)
+	case SubtitleVTT:
+		return parseVTT(text)
+	case SubtitleASS:
+		return parseASS(text)
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+}
+
+func parseSRT(text string) ([]*Cue, error) {
+	cues := make([]*Cue, 0)
+	for _, block := range splitBlocks(text) {
+		lines := strings.Split(block, "\n")
+		i := 0
+		if !strings.Contains(lines[0], "-->") {
+			i++
+		}
+		if i >= len(lines) || !strings.Contains(lines[i], "-->") {
+			continue
+		}
+		start, end, err := parseCueTiming(lines[i])
+		if err != nil {
+			return nil, err
+		}
+		body := strings.Join(lines[i+1:], "\n")
+		cues = append(cues, newSubtitleCue("", cleanMarkup(body), start, end))
+	}
+	return cues, nil
+}
+
+func parseVTT(text string) ([]*Cue, error) {
+	cues := make([]*Cue, 0)
+	blocks := splitBlocks(text)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0], "WEBVTT") {
+		return nil, fmt.Errorf("invalid WebVTT file: missing header")
+	}
+	for _, block := range blocks[1:] {
+		lines := strings.Split(block, "\n")
+		if first := lines[0]; strings.HasPrefix(first, "NOTE") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+		i := 0
+		if !strings.Contains(lines[0], "-->") {
+			i++
+		}
+		if i >= len(lines) || !strings.Contains(lines[i], "-->") {
+			continue
+		}
+		start, end, err := parseCueTiming(lines[i])
+		if err != nil {
+			return nil, err
+		}
+		body := strings.Join(lines[i+1:], "\n")
+		speaker := ""
+		if m := vttVoice.FindStringSubmatch(body); m != nil {
+			speaker = strings.TrimSpace(m[1])
+		}
+		cues = append(cues, newSubtitleCue(speaker, cleanMarkup(body), start, end))
+	}
+	return cues, nil
+}
+
+func parseASS(text string) ([]*Cue, error) {
+	cues := make([]*Cue, 0)
+	var format []string
+	inEvents := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Format":
+			format = strings.Split(value, ",")
+			for i := range format {
+				format[i] = strings.ToLower(strings.TrimSpace(format[i]))
+			}
+		case "Dialogue":
+			if format == nil {
+				return nil, fmt.Errorf("invalid ASS file: dialogue before format line")
+			}
+			fields := strings.SplitN(value, ",", len(format))
+			if len(fields) != len(format) {
+				return nil, fmt.Errorf("invalid ASS dialogue line: %s", line)
+			}
+			event := make(map[string]string, len(format))
+			for i, name := range format {
+				event[name] = strings.TrimSpace(fields[i])
+			}
+			start, err := parseSubtitleTime(event["start"])
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseSubtitleTime(event["end"])
+			if err != nil {
+				return nil, err
+			}
+			body := assOverride.ReplaceAllString(event["text"], "")
+			body = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(body)
+			cues = append(cues, newSubtitleCue(event["name"], strings.TrimSpace(body), start, end))
+		}
+	}
+	return cues, nil
+}
+
+// newSubtitleCue builds a cue, taking the speaker from a "NAME:" prefix
+// when the format did not name one.
+func newSubtitleCue(speaker, text string, start, end float64) *Cue {
+	if speaker == "" {
+		if m := speakerPrefix.FindStringSubmatch(text); m != nil {
+			speaker = strings.TrimSpace(m[1])
+			text = text[len(m[0]):]
+		}
+	}
+	return NewCue(speaker, strings.TrimSpace(text), start, end)
+}
+
+func splitBlocks(text string) []string {
+	blocks := make([]string, 0)
+	for _, block := range blankLine.Split(strings.TrimSpace(text), -1) {
+		if block = strings.TrimSpace(block); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func cleanMarkup(text string) string {
+	text = markupTag.ReplaceAllString(text, "")
+	text = assOverride.ReplaceAllString(text, "")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+// parseCueTiming parses an SRT or WebVTT "start --> end" line, ignoring any
+// cue settings after the end time.
+func parseCueTiming(line string) (float64, float64, error) {
+	from, to, _ := strings.Cut(line, "-->")
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid cue timing: %s", line)
+	}
+	start, err := parseSubtitleTime(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseSubtitleTime(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseSubtitleTime parses hh:mm:ss,mmm (SRT), [hh:]mm:ss.mmm (WebVTT) and
+// h:mm:ss.cc (ASS) into seconds.
+func parseSubtitleTime(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid subtitle time: %s", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid subtitle time: %s", s)
+	}
+	total := seconds
+	scale := 60.0
+	for i := len(parts) - 2; i >= 0; i-- {
+		v, err := strconv.Atoi(parts[i])
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid subtitle time: %s", s)
+		}
+		total += float64(v) * scale
+		scale *= 60
+	}
+	return total, nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSRT(t *testing.T) {
+	data := "\ufeff1\r\n00:00:01,500 --> 00:00:03,000\r\nALICE: Hello <i>there</i>.\r\n\r\n" +
+		"2\r\n00:01:02,250 --> 00:01:04,000 X1:0\r\nNo speaker here,\r\njust two lines.\r\n"
+	cues, err := ParseSubtitles([]byte(data), SubtitleSRT)
+	if err != nil {
+		t.Fatalf("Failed to parse SRT: %v", err)
+	}
+	if len(cues) != 2 {
+		t.Fatalf("Expected 2 cues, got %d", len(cues))
+	}
+	if cues[0].Start != 1.5 || cues[0].End != 3.0 {
+		t.Errorf("Expected 1.5-3.0, got %f-%f", cues[0].Start, cues[0].End)
+	}
+	if cues[0].Speaker != "ALICE" || cues[0].Text != "Hello there." {
+		t.Errorf("Expected ALICE 'Hello there.', got %s '%s'", cues[0].Speaker, cues[0].Text)
+	}
+	if cues[1].Start != 62.25 || cues[1].Speaker != "" || cues[1].Text != "No speaker here,\njust two lines." {
+		t.Errorf("Unexpected second cue: %+v", cues[1])
+	}
+}
+
+func TestParseVTT(t *testing.T) {
+	data := "WEBVTT - Episode 1\n\nNOTE translated by hand\n\n" +
+		"intro\n00:05.000 --> 00:07.500 align:start\n<v Bob>Fish &amp; chips?</v>\n\n" +
+		"01:00:00.000 --> 01:00:01.000\nCAROL: Later.\n"
+	cues, err := ParseSubtitles([]byte(data), SubtitleVTT)
+	if err != nil {
+		t.Fatalf("Failed to parse WebVTT: %v", err)
+	}
+	if len(cues) != 2 {
+		t.Fatalf("Expected 2 cues, got %d", len(cues))
+	}
+	if cues[0].Start != 5 || cues[0].End != 7.5 || cues[0].Speaker != "Bob" || cues[0].Text != "Fish & chips?" {
+		t.Errorf("Unexpected first cue: %+v", cues[0])
+	}
+	if cues[1].Start != 3600 || cues[1].Speaker != "CAROL" || cues[1].Text != "Later." {
+		t.Errorf("Unexpected second cue: %+v", cues[1])
+	}
+	if _, err := ParseSubtitles([]byte("00:01.000 --> 00:02.000\nHi\n"), SubtitleVTT); err == nil {
+		t.Error("Expected error for a file without the WEBVTT header")
+	}
+}
+
+func TestParseASS(t *testing.T) {
+	data := "[Script Info]\nTitle: Test\n\n[V4+ Styles]\nFormat: Name, Fontname\nStyle: Default,Arial\n\n" +
+		"[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,ignored\n" +
+		"Dialogue: 0,0:00:02.50,0:00:04.00,Default,Dave,0,0,0,,{\\i1}Wait,{\\i0} what?\\NReally?\n"
+	cues, err := ParseSubtitles([]byte(data), SubtitleASS)
+	if err != nil {
+		t.Fatalf("Failed to parse ASS: %v", err)
+	}
+	if len(cues) != 1 {
+		t.Fatalf("Expected 1 cue, got %d", len(cues))
+	}
+	if cues[0].Start != 2.5 || cues[0].End != 4 || cues[0].Speaker != "Dave" || cues[0].Text != "Wait, what?\nReally?" {
+		t.Errorf("Unexpected cue: %+v", cues[0])
+	}
+}
+
+func TestParseSubtitleFileDetectsFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "episode.txt")
+	os.WriteFile(path, []byte("WEBVTT\n\n00:01.000 --> 00:02.000\nHi\n"), 0644)
+	cues, err := ParseSubtitleFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse subtitle file: %v", err)
+	}
+	if len(cues) != 1 || cues[0].Text != "Hi" {
+		t.Errorf("Expected one 'Hi' cue, got %+v", cues)
+	}
+	if _, err := parseSubtitleTime("00:61,000"); err == nil {
+		t.Error("Expected error for seconds out of range")
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SubtitleEvents returns one event per active take, spanning the take on
+// the timeline, with the character's name as speaker and the text of the
+// character's cue that overlaps the take most.
+func (p *Project) SubtitleEvents() []*Cue {
+	recordings := p.ActiveRecordings()
+	sort.SliceStable(recordings, func(i, j int) bool {
+		return recordings[i].Timecode < recordings[j].Timecode
+	})
+	events := make([]*Cue, 0, len(recordings))
+	for _, r := range recordings {
+		event := &Cue{
+			ID:          r.ID,
+			CharacterID: r.CharacterID,
+			Start:       r.Timecode,
+			End:         r.Timecode + r.Duration,
+		}
+		if c := p.GetCharacter(r.CharacterID); c != nil {
+			event.Speaker = c.Name
+		}
+		if cue := p.cueForRecording(r); cue != nil {
+			event.Text = cue.Text
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (p *Project) cueForRecording(r *Recording) *Cue {
+	var best *Cue
+	var bestOverlap float64
+	for _, c := range p.GetCuesForCharacter(r.CharacterID) {
+		if c.Unplaced {
+			continue
+		}
+		overlap := math.Min(c.End, r.Timecode+r.Duration) - math.Max(c.Start, r.Timecode)
+		if overlap > bestOverlap {
+			best, bestOverlap = c, overlap
+		}
+	}
+	return best
+}
+
+// ExportSubtitles writes the project's takes to dst as an SRT, WebVTT or
+// ASS file.
+func (p *Project) ExportSubtitles(dst, format string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteSubtitles(f, format); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+func (p *Project) WriteSubtitles(w io.Writer, format string) error {
+	bw := bufio.NewWriter(w)
+	events := p.SubtitleEvents()
+	switch format {
+	case SubtitleSRT:
+		writeSRT(bw, events)
+	case SubtitleVTT:
+		writeVTT(bw, events)
+	case SubtitleASS:
+		writeASS(bw, p, events)
+	default:
+		return fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+	return bw.Flush()
+}
+
+func writeSRT(w io.Writer, events []*Cue) {
+	for i, e := range events {
+		text := e.Text
+		if e.Speaker != "" {
+			text = strings.TrimSpace(e.Speaker + ": " + text)
+		}
+		fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1, formatSubtitleTime(e.Start, ","), formatSubtitleTime(e.End, ","), text)
+	}
+}
+
+func writeVTT(w io.Writer, events []*Cue) {
+	fmt.Fprint(w, "WEBVTT\n\n")
+	for _, e := range events {
+		text := escapeVTT(e.Text)
+		if e.Speaker != "" {
+			text = "<v " + escapeVTT(e.Speaker) + ">" + text
+		}
+		fmt.Fprintf(w, "%s --> %s\n%s\n\n", formatSubtitleTime(e.Start, "."), formatSubtitleTime(e.End, "."), text)
+	}
+}
+
+func escapeVTT(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// formatSubtitleTime formats seconds as hh:mm:ss followed by sep and
+// milliseconds.
+func formatSubtitleTime(seconds float64, sep string) string {
+	ms := int64(math.Round(math.Max(seconds, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+const (
+	assStyleFormat = "Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding"
+	assEventFormat = "Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text"
+	assWhite       = "&H00FFFFFF"
+)
+
+// writeASS writes an ASS script with one style per character, coloured with
+// the character's colour.
+func writeASS(w io.Writer, p *Project, events []*Cue) {
+	width, height := 1920, 1080
+	if p.Video != nil && p.Video.Width > 0 && p.Video.Height > 0 {
+		width, height = p.Video.Width, p.Video.Height
+	}
+	fmt.Fprintf(w, "[Script Info]\nTitle: %s\nScriptType: v4.00+\nWrapStyle: 0\nPlayResX: %d\nPlayResY: %d\nScaledBorderAndShadow: yes\n\n",
+		assField(p.Title), width, height)
+
+	fmt.Fprintf(w, "[V4+ Styles]\nFormat: %s\n", assStyleFormat)
+	fmt.Fprint(w, assStyle("Default", assWhite))
+	styles := make(map[string]string, len(p.Characters))
+	used := map[string]bool{"Default": true}
+	for _, c := range p.Characters {
+		base := assField(c.Name)
+		if base == "" {
+			base = "Character"
+		}
+		name := base
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s %d", base, i)
+		}
+		used[name] = true
+		styles[c.ID] = name
+		fmt.Fprint(w, assStyle(name, assColour(c.Color)))
+	}
+
+	fmt.Fprintf(w, "\n[Events]\nFormat: %s\n", assEventFormat)
+	for _, e := range events {
+		style := styles[e.CharacterID]
+		if style == "" {
+			style = "Default"
+		}
+		text := strings.ReplaceAll(strings.NewReplacer("{", "(", "}", ")").Replace(e.Text), "\n", `\N`)
+		fmt.Fprintf(w, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s\n",
+			formatASSTime(e.Start), formatASSTime(e.End), style, assField(e.Speaker), text)
+	}
+}
+
+func assStyle(name, colour string) string {
+	return fmt.Sprintf("Style: %s,Arial,54,%s,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,1,2,40,40,40,1\n", name, colour)
+}
+
+// assField strips characters that would break a comma-separated ASS line.
+func assField(s string) string {
+	return strings.TrimSpace(strings.NewReplacer(",", " ", "\n", " ").Replace(s))
+}
+
+// assColour converts #rrggbb to the &HAABBGGRR form ASS uses.
+func assColour(hex string) string {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &r, &g, &b); err != nil {
+		return assWhite
+	}
+	return fmt.Sprintf("&H00%02X%02X%02X", b, g, r)
+}
+
+// formatASSTime formats seconds as h:mm:ss.cc.
+func formatASSTime(seconds float64) string {
+	cs := int64(math.Round(math.Max(seconds, 0) * 100))
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func subtitleProject(t *testing.T) *Project {
+	t.Helper()
+	p := NewProject("Dub", t.TempDir())
+	alice := NewCharacter("Alice", "#ff8000")
+	bob := NewCharacter("Bob", "#00d4ff")
+	p.AddCharacter(alice)
+	p.AddCharacter(bob)
+	p.Cues = []*Cue{
+		{ID: "c1", CharacterID: alice.ID, Start: 1, End: 3, Text: "Hello <there>"},
+		{ID: "c2", CharacterID: alice.ID, Start: 3, End: 4, Text: "Other line"},
+	}
+	p.AddRecording(NewRecording(bob.ID, "/b.wav", 65.5, 1.25))
+	p.AddRecording(NewRecording(alice.ID, "/a.wav", 1.2, 2.0))
+	return p
+}
+
+func TestWriteSubtitlesSRT(t *testing.T) {
+	p := subtitleProject(t)
+	var buf bytes.Buffer
+	if err := p.WriteSubtitles(&buf, SubtitleSRT); err != nil {
+		t.Fatalf("Failed to write SRT: %v", err)
+	}
+	want := "1\n00:00:01,200 --> 00:00:03,200\nAlice: Hello <there>\n\n" +
+		"2\n00:01:05,500 --> 00:01:06,750\nBob:\n\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected SRT:\n%s", buf.String())
+	}
+}
+
+func TestWriteSubtitlesVTTRoundTrip(t *testing.T) {
+	p := subtitleProject(t)
+	var buf bytes.Buffer
+	if err := p.WriteSubtitles(&buf, SubtitleVTT); err != nil {
+		t.Fatalf("Failed to write WebVTT: %v", err)
+	}
+	cues, err := ParseSubtitles(buf.Bytes(), SubtitleVTT)
+	if err != nil {
+		t.Fatalf("Failed to parse written WebVTT: %v", err)
+	}
+	if len(cues) != 2 {
+		t.Fatalf("Expected 2 cues, got %d", len(cues))
+	}
+	if cues[0].Speaker != "Alice" || cues[0].Text != "Hello <there>" || cues[0].Start != 1.2 || cues[0].End != 3.2 {
+		t.Errorf("Unexpected first cue: %+v", cues[0])
+	}
+}
+
+func TestWriteSubtitlesASS(t *testing.T) {
+	p := subtitleProject(t)
+	var buf bytes.Buffer
+	if err := p.WriteSubtitles(&buf, SubtitleASS); err != nil {
+		t.Fatalf("Failed to write ASS: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Style: Alice,Arial,54,&H000080FF,") {
+		t.Errorf("Expected Alice's colour as an ASS style, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Dialogue: 0,0:01:05.50,0:01:06.75,Bob,Bob,0,0,0,,\n") {
+		t.Errorf("Expected Bob's take as a dialogue event, got:\n%s", out)
+	}
+	cues, err := ParseSubtitles(buf.Bytes(), SubtitleASS)
+	if err != nil {
+		t.Fatalf("Failed to parse written ASS: %v", err)
+	}
+	if len(cues) != 2 || cues[0].Speaker != "Alice" || cues[0].Text != "Hello <there>" {
+		t.Errorf("Unexpected round trip: %+v", cues)
+	}
+	if err := p.WriteSubtitles(&buf, "sub"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FrameRate is an exact video frame rate such as 24000/1001 (23.976).
+type FrameRate struct {
+	Num int `json:"num"`
+	Den int `json:"den"`
+}
+
+var DefaultFrameRate = FrameRate{Num: 30, Den: 1}
+
+func (r FrameRate) FPS() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Nominal is the whole-number frame count per timecode second, e.g. 30 for
+// 29.97.
+func (r FrameRate) Nominal() int {
+	return int(math.Round(r.FPS()))
+}
+
+// DropFrame reports whether timecode at this rate is counted in drop-frame,
+// which SMPTE defines for 29.97 and 59.94.
+func (r FrameRate) DropFrame() bool {
+	return r.Den == 1001 && r.Nominal()%30 == 0
+}
+
+func (r FrameRate) dropCount() int {
+	return r.Nominal() / 15
+}
+
+// Timecode is an SMPTE HH:MM:SS:FF timecode. Drop-frame timecodes use a
+// semicolon before the frame field.
+type Timecode struct {
+	Hours     int  `json:"hours"`
+	Minutes   int  `json:"minutes"`
+	Seconds   int  `json:"seconds"`
+	Frames    int  `json:"frames"`
+	DropFrame bool `json:"drop_frame"`
+}
+
+func (tc Timecode) String() string {
+	sep := ":"
+	if tc.DropFrame {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", tc.Hours, tc.Minutes, tc.Seconds, sep, tc.Frames)
+}
+
+// Filename formats the timecode with dashes so it is safe in file names.
+func (tc Timecode) Filename() string {
+	return fmt.Sprintf("%02d-%02d-%02d-%02d", tc.Hours, tc.Minutes, tc.Seconds, tc.Frames)
+}
+
+// FramesToTimecode converts a frame count to a timecode, wrapping at 24
+// hours.
+func FramesToTimecode(frames int64, rate FrameRate) Timecode {
+	nominal := int64(rate.Nominal())
+	if frames < 0 {
+		frames = 0
+	}
+	if rate.DropFrame() {
+		drop := int64(rate.dropCount())
+		perMinute := nominal*60 - drop
+		perTenMinutes := perMinute*10 + drop
+		tens := frames / perTenMinutes
+		rem := frames % perTenMinutes
+		frames += drop * 9 * tens
+		if rem > drop {
+			frames += drop * ((rem - drop) / perMinute)
+		}
+	}
+	totalSeconds := frames / nominal
+	return Timecode{
+		Hours:     int(totalSeconds/3600) % 24,
+		Minutes:   int(totalSeconds/60) % 60,
+		Seconds:   int(totalSeconds % 60),
+		Frames:    int(frames % nominal),
+		DropFrame: rate.DropFrame(),
+	}
+}
+
+func (tc Timecode) ToFrames(rate FrameRate) int64 {
+	nominal := int64(rate.Nominal())
+	totalSeconds := int64(tc.Hours)*3600 + int64(tc.Minutes)*60 + int64(tc.Seconds)
+	frames := totalSeconds*nominal + int64(tc.Frames)
+	if rate.DropFrame() {
+		totalMinutes := int64(tc.Hours)*60 + int64(tc.Minutes)
+		frames -= int64(rate.dropCount()) * (totalMinutes - totalMinutes/10)
+	}
+	return frames
+}
+
+func SecondsToTimecode(seconds float64, rate FrameRate) Timecode {
+	frames := int64(math.Floor(seconds*rate.FPS() + 1e-6))
+	return FramesToTimecode(frames, rate)
+}
+
+func (tc Timecode) ToSeconds(rate FrameRate) float64 {
+	return float64(tc.ToFrames(rate)) / rate.FPS()
+}
+
+// ParseTimecode reads HH:MM:SS:FF, accepting ':', ';', '.' or '-' as
+// separators.
+func ParseTimecode(s string, rate FrameRate) (Timecode, error) {
+	fields := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool {
+		return r == ':' || r == ';' || r == '.' || r == '-'
+	})
+	if len(fields) != 4 {
+		return Timecode{}, fmt.Errorf("invalid timecode: %s", s)
+	}
+	values := make([]int, 4)
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 {
+			return Timecode{}, fmt.Errorf("invalid timecode: %s", s)
+		}
+		values[i] = v
+	}
+	tc := Timecode{
+		Hours:     values[0],
+		Minutes:   values[1],
+		Seconds:   values[2],
+		Frames:    values[3],
+		DropFrame: rate.DropFrame(),
+	}
+	if tc.Hours > 23 || tc.Minutes > 59 || tc.Seconds > 59 || tc.Frames >= rate.Nominal() {
+		return Timecode{}, fmt.Errorf("timecode out of range: %s", s)
+	}
+	if tc.DropFrame && tc.Seconds == 0 && tc.Minutes%10 != 0 && tc.Frames < rate.dropCount() {
+		return Timecode{}, fmt.Errorf("timecode %s does not exist in drop-frame", s)
+	}
+	return tc, nil
+}
+
+// FrameRate is the video's frame rate, or DefaultFrameRate without a video.
+func (p *Project) FrameRate() FrameRate {
+	if p.Video != nil && p.Video.FrameRateNum > 0 && p.Video.FrameRateDen > 0 {
+		return FrameRate{Num: p.Video.FrameRateNum, Den: p.Video.FrameRateDen}
+	}
+	return DefaultFrameRate
+}
+
+func (p *Project) startFrames(rate FrameRate) int64 {
+	if p.StartTimecode == "" {
+		return 0
+	}
+	start, err := ParseTimecode(p.StartTimecode, rate)
+	if err != nil {
+		return 0
+	}
+	return start.ToFrames(rate)
+}
+
+// Timecode converts a timeline position in seconds to the project's
+// timecode, including its start offset.
+func (p *Project) Timecode(seconds float64) Timecode {
+	rate := p.FrameRate()
+	frames := int64(math.Floor(seconds*rate.FPS()+1e-6)) + p.startFrames(rate)
+	return FramesToTimecode(frames, rate)
+}
+
+// TimelineSeconds converts a project timecode back to a timeline position.
+func (p *Project) TimelineSeconds(s string) (float64, error) {
+	rate := p.FrameRate()
+	tc, err := ParseTimecode(s, rate)
+	if err != nil {
+		return 0, err
+	}
+	frames := tc.ToFrames(rate) - p.startFrames(rate)
+	if frames < 0 {
+		return 0, fmt.Errorf("timecode %s is before the project start", s)
+	}
+	return float64(frames) / rate.FPS(), nil
+}
+
+func (p *Project) SetStartTimecode(s string) error {
+	if s != "" {
+		if _, err := ParseTimecode(s, p.FrameRate()); err != nil {
+			return err
+		}
+	}
+	p.StartTimecode = s
+	p.UpdatedAt = time.Now()
+	return nil
+}
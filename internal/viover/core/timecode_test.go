@@ -0,0 +1,94 @@
+package core
+
+import "testing"
+
+func TestFramesToTimecode(t *testing.T) {
+	cases := []struct {
+		frames int64
+		rate   FrameRate
+		want   string
+	}{
+		{0, FrameRate{30, 1}, "00:00:00:00"},
+		{29, FrameRate{30, 1}, "00:00:00:29"},
+		{30*3600 + 15, FrameRate{30, 1}, "01:00:00:15"},
+		{24*60 + 23, FrameRate{24, 1}, "00:01:00:23"},
+		{25 * 90, FrameRate{25, 1}, "00:01:30:00"},
+		{24 * 60, FrameRate{24000, 1001}, "00:01:00:00"},
+		{1799, FrameRate{30000, 1001}, "00:00:59;29"},
+		{1800, FrameRate{30000, 1001}, "00:01:00;02"},
+		{17982, FrameRate{30000, 1001}, "00:10:00;00"},
+		{107892, FrameRate{30000, 1001}, "01:00:00;00"},
+		{3600, FrameRate{60000, 1001}, "00:01:00;04"},
+	}
+	for _, c := range cases {
+		got := FramesToTimecode(c.frames, c.rate)
+		if got.String() != c.want {
+			t.Errorf("FramesToTimecode(%d, %v) = %s, want %s", c.frames, c.rate, got, c.want)
+		}
+		if back := got.ToFrames(c.rate); back != c.frames {
+			t.Errorf("%s.ToFrames(%v) = %d, want %d", got, c.rate, back, c.frames)
+		}
+	}
+}
+
+func TestSecondsToTimecode(t *testing.T) {
+	if tc := SecondsToTimecode(65.5, FrameRate{25, 1}); tc.String() != "00:01:05:12" {
+		t.Errorf("Expected 00:01:05:12, got %s", tc)
+	}
+	if tc := SecondsToTimecode(3723.0, FrameRate{24, 1}); tc.Filename() != "01-02-03-00" {
+		t.Errorf("Expected 01-02-03-00, got %s", tc.Filename())
+	}
+	rate := FrameRate{30000, 1001}
+	tc := SecondsToTimecode(600.0, rate)
+	if tc.String() != "00:10:00;00" {
+		t.Errorf("Expected 00:10:00;00, got %s", tc)
+	}
+	if s := tc.ToSeconds(rate); s < 599.999 || s > 600.001 {
+		t.Errorf("Expected 600 seconds, got %f", s)
+	}
+}
+
+func TestParseTimecode(t *testing.T) {
+	tc, err := ParseTimecode("01:02:03:04", FrameRate{25, 1})
+	if err != nil {
+		t.Fatalf("Failed to parse timecode: %v", err)
+	}
+	if tc.Hours != 1 || tc.Minutes != 2 || tc.Seconds != 3 || tc.Frames != 4 {
+		t.Errorf("Unexpected timecode: %+v", tc)
+	}
+	if _, err := ParseTimecode("00-00-01-10", FrameRate{24, 1}); err != nil {
+		t.Errorf("Expected dashed timecode to parse: %v", err)
+	}
+	for _, bad := range []string{"", "00:00:00", "00:60:00:00", "00:00:00:25", "aa:00:00:00"} {
+		if _, err := ParseTimecode(bad, FrameRate{25, 1}); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+	if _, err := ParseTimecode("00:01:00;00", FrameRate{30000, 1001}); err == nil {
+		t.Error("Expected error for a dropped drop-frame timecode")
+	}
+}
+
+func TestProjectTimecodeOffset(t *testing.T) {
+	p := NewProject("Test", "/tmp")
+	p.SetVideo(&Video{FrameRateNum: 24, FrameRateDen: 1})
+	if err := p.SetStartTimecode("01:00:00:00"); err != nil {
+		t.Fatalf("Failed to set start timecode: %v", err)
+	}
+	if tc := p.Timecode(90.5); tc.String() != "01:01:30:12" {
+		t.Errorf("Expected 01:01:30:12, got %s", tc)
+	}
+	seconds, err := p.TimelineSeconds("01:01:30:12")
+	if err != nil {
+		t.Fatalf("Failed to convert timecode: %v", err)
+	}
+	if seconds != 90.5 {
+		t.Errorf("Expected 90.5 seconds, got %f", seconds)
+	}
+	if _, err := p.TimelineSeconds("00:59:59:00"); err == nil {
+		t.Error("Expected error for timecode before the project start")
+	}
+	if err := p.SetStartTimecode("bogus"); err == nil {
+		t.Error("Expected error for invalid start timecode")
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+const (
+	wavHeaderSize  = 44
+	wavFormatPCM   = 1
+	wavFormatFloat = 3
+)
+
+// WAVWriter streams PCM data into a WAV file. The RIFF and data chunk sizes
+// are rewritten on every Flush so the file stays playable if the process dies
+// mid-take. A bit depth of 32 is written as IEEE float.
+type WAVWriter struct {
+	file       *os.File
+	sampleRate int
+	bitDepth   int
+	channels   int
+	dataBytes  int64
+}
+
+func NewWAVWriter(path string, sampleRate, bitDepth, channels int) (*WAVWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &WAVWriter{
+		file:       file,
+		sampleRate: sampleRate,
+		bitDepth:   bitDepth,
+		channels:   channels,
+	}
+	if _, err := file.Write(w.header()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WAVWriter) header() []byte {
+	h := make([]byte, wavHeaderSize)
+	blockAlign := w.channels * w.bitDepth / 8
+	format := wavFormatPCM
+	if w.bitDepth == 32 {
+		format = wavFormatFloat
+	}
+	copy(h[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:8], uint32(36+w.dataBytes))
+	copy(h[8:12], "WAVE")
+	copy(h[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:20], 16)
+	binary.LittleEndian.PutUint16(h[20:22], uint16(format))
+	binary.LittleEndian.PutUint16(h[22:24], uint16(w.channels))
+	binary.LittleEndian.PutUint32(h[24:28], uint32(w.sampleRate))
+	binary.LittleEndian.PutUint32(h[28:32], uint32(w.sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(h[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(h[34:36], uint16(w.bitDepth))
+	copy(h[36:40], "data")
+	binary.LittleEndian.PutUint32(h[40:44], uint32(w.dataBytes))
+	return h
+}
+
+func (w *WAVWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.dataBytes += int64(n)
+	return n, err
+}
+
+func (w *WAVWriter) Flush() error {
+	if err := writeWAVSizes(w.file, w.dataBytes); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+func (w *WAVWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *WAVWriter) Frames() int64 {
+	return w.dataBytes / int64(w.channels*w.bitDepth/8)
+}
+
+func (w *WAVWriter) Duration() float64 {
+	return float64(w.Frames()) / float64(w.sampleRate)
+}
+
+func writeWAVSizes(file *os.File, dataBytes int64) error {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(36+dataBytes))
+	if _, err := file.WriteAt(size[:], 4); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(size[:], uint32(dataBytes))
+	_, err := file.WriteAt(size[:], 40)
+	return err
+}
+
+// RepairWAV rewrites the header sizes of a WAV file written by WAVWriter from
+// its actual length on disk and returns the recovered duration in seconds.
+func RepairWAV(path string) (float64, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	h := make([]byte, wavHeaderSize)
+	if _, err := file.ReadAt(h, 0); err != nil {
+		return 0, fmt.Errorf("invalid WAV file: %s", path)
+	}
+	if string(h[0:4]) != "RIFF" || string(h[8:12]) != "WAVE" || string(h[36:40]) != "data" {
+		return 0, fmt.Errorf("invalid WAV file: %s", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	blockAlign := int64(binary.LittleEndian.Uint16(h[32:34]))
+	sampleRate := int64(binary.LittleEndian.Uint32(h[24:28]))
+	if blockAlign == 0 || sampleRate == 0 {
+		return 0, fmt.Errorf("invalid WAV file: %s", path)
+	}
+	dataBytes := info.Size() - wavHeaderSize
+	dataBytes -= dataBytes % blockAlign
+	if err := file.Truncate(wavHeaderSize + dataBytes); err != nil {
+		return 0, err
+	}
+	if err := writeWAVSizes(file, dataBytes); err != nil {
+		return 0, err
+	}
+	return float64(dataBytes/blockAlign) / float64(sampleRate), nil
+}
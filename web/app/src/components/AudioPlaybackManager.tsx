@@ -1,6 +1,6 @@
 import { createSignal, createEffect, onCleanup } from "solid-js";
 import { core } from "../../wailsjs/go/models";
-import { GetAudioData } from "../../wailsjs/go/adapters/App";
+import { GetAudioURL } from "../../wailsjs/go/adapters/App";
 
 interface AudioPlaybackManagerProps {
   recordings: core.Recording[];
@@ -75,35 +75,26 @@ export function AudioPlaybackManager(props: AudioPlaybackManagerProps) {
     try {
       console.log("[AudioManager] Loading audio for", recording.id);
 
-      let base64Data: string | null = null;
+      let audioUrl = "";
       try {
-        base64Data = await GetAudioData(recording.id);
+        audioUrl = await GetAudioURL(recording.id);
       } catch (e) {
-        console.error("[AudioManager] GetAudioData threw error:", e);
+        console.error("[AudioManager] GetAudioURL threw error:", e);
       }
 
-      console.log("[AudioManager] GetAudioData returned base64 string, length:", base64Data?.length);
-
-      if (!base64Data || base64Data.length === 0) {
-        console.error("[AudioManager] No audio data for", recording.id);
+      if (!audioUrl) {
+        console.error("[AudioManager] No audio URL for", recording.id);
         loadingIds.delete(recording.id);
         return;
       }
 
-      const dataUrl = `data:audio/wav;base64,${base64Data}`;
-      console.log("[AudioManager] Data URL created, length:", dataUrl.length);
-
       const audio = new Audio();
       audio.preload = "auto";
-
-      const dataForDebug = {
-        base64Length: base64Data.length,
-        audioUrl: dataUrl.substring(0, 100) + "...",
-      };
+      audio.crossOrigin = "anonymous";
 
       const state: AudioState = {
         element: audio,
-        audioUrl: dataUrl,
+        audioUrl,
         isReady: false,
         recordingId: recording.id,
         timecode: recording.timecode,
@@ -148,11 +139,10 @@ export function AudioPlaybackManager(props: AudioPlaybackManagerProps) {
         console.error("[AudioManager] Audio load error for", recording.id);
         console.error("[AudioManager] Error code:", err?.code, "- 1=ABORTED, 2=NETWORK, 3=DECODE, 4=SRC_NOT_SUPPORTED");
         console.error("[AudioManager] Error message:", err?.message);
-        console.error("[AudioManager] Audio URL prefix:", dataForDebug.audioUrl);
-        console.error("[AudioManager] Base64 length was:", dataForDebug.base64Length);
+        console.error("[AudioManager] Audio URL:", audioUrl);
       };
 
-      audio.src = dataUrl;
+      audio.src = audioUrl;
       audioCache.set(recording.id, state);
       loadingIds.delete(recording.id);
     } catch (e) {
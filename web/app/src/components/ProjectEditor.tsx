@@ -7,7 +7,7 @@ import {
   onMount,
   onCleanup,
 } from "solid-js";
-import { core } from "../../wailsjs/go/models";
+import { adapters, core } from "../../wailsjs/go/models";
 import {
   GetCurrentProject,
   SelectVideo,
@@ -16,9 +16,11 @@ import {
   UpdateCharacter,
   DeleteCharacter,
   RecordAudio,
+  RecordAudioWithOptions,
   StopRecording,
   DeleteRecording,
   ExportRecordings,
+  ExportRecordingsWithOptions,
   ListDevices,
   SelectDevice,
   GetSelectedDevice,
@@ -28,6 +30,14 @@ import {
   UpdateRecordingGain,
   SetMicrophoneGain,
   GetMicrophoneGain,
+  SetAudioFormat,
+  GetPreferences,
+  UpdatePreferences,
+  ImportSubtitles,
+  ImportScript,
+  PlaceCue,
+  ExportSubtitles,
+  ExportCueSheet,
 } from "../../wailsjs/go/adapters/App";
 import { EventsOn } from "../../wailsjs/runtime/runtime";
 import { VideoControls } from "./VideoControls";
@@ -61,7 +71,25 @@ const DEFAULT_SIDEBAR_WIDTH = 280;
 const MIN_SIDEBAR_WIDTH = 280;
 const MAX_SIDEBAR_WIDTH = 450;
 const SEEK_STEP = 5;
+const FORMAT_OPTIONS: {
+  key: "sample_rate" | "bit_depth" | "channels";
+  values: [number, string][];
+}[] = [
+  { key: "sample_rate", values: [[44100, "44.1k"], [48000, "48k"], [96000, "96k"]] },
+  { key: "bit_depth", values: [[16, "16-bit"], [24, "24-bit"], [32, "32f"]] },
+  { key: "channels", values: [[1, "Mono"], [2, "Stereo"]] },
+];
 const FINE_SEEK_STEP = 1;
+const PUNCH_PRE_ROLL = 3;
+const PUNCH_POST_ROLL = 1;
+// ORIGINAL_DUCK_DB is how far the video's own audio is lowered under the
+// dub when exporting a mixed video.
+const ORIGINAL_DUCK_DB = -12;
+const VIDEO_EXPORTS = [
+  { audio: "replace", label: "Video, dub only" },
+  { audio: "mix", label: "Video, dub over original" },
+  { audio: "add", label: "Video, dub as extra track" },
+];
 
 export function ProjectEditor(props: ProjectEditorProps) {
   const [project, setProject] = createSignal<core.Project | null>(null);
@@ -84,6 +112,9 @@ export function ProjectEditor(props: ProjectEditorProps) {
   const [showAddCharacter, setShowAddCharacter] = createSignal(false);
   const [newCharacterName, setNewCharacterName] = createSignal("");
   const [showCountdown, setShowCountdown] = createSignal(false);
+  const [punchIn, setPunchIn] = createSignal<number | undefined>(undefined);
+  const [punchOut, setPunchOut] = createSignal<number | undefined>(undefined);
+  const [punchStatus, setPunchStatus] = createSignal("");
   const [recordingStartTime, setRecordingStartTime] = createSignal<
     number | undefined
   >(undefined);
@@ -127,6 +158,20 @@ export function ProjectEditor(props: ProjectEditorProps) {
     setMicGainDB(gain || 0);
   };
 
+  const loadMasterVolume = async () => {
+    const prefs = await GetPreferences();
+    setMasterVolume(prefs.master_volume ?? 1);
+  };
+
+  // saveMasterVolume stores the slider once it is released, so plain
+  // exports and the next session use the same level.
+  const saveMasterVolume = async (volume: number) => {
+    const prefs = await GetPreferences();
+    await UpdatePreferences(
+      core.Preferences.createFrom({ ...prefs, master_volume: volume }),
+    );
+  };
+
   const handleSelectVideo = async () => {
     const video = await SelectVideo();
     if (video) {
@@ -195,6 +240,45 @@ export function ProjectEditor(props: ProjectEditorProps) {
     await loadProject();
   };
 
+  const punchReady = () => {
+    const inPoint = punchIn();
+    const outPoint = punchOut();
+    return inPoint !== undefined && outPoint !== undefined && outPoint > inPoint;
+  };
+
+  // The backend counts the pre-roll from the start of capture, so the video
+  // starts that far ahead of the in-point and plays on through the post-roll.
+  const handlePunchRecord = async () => {
+    const charId = selectedCharacterId();
+    const inPoint = punchIn();
+    const outPoint = punchOut();
+    if (!charId || isRecording() || !punchReady()) return;
+    const preRoll = Math.min(PUNCH_PRE_ROLL, inPoint!);
+    handleSeek(inPoint! - preRoll);
+    setRecordingStartTime(inPoint);
+    setIsRecording(true);
+    setPunchStatus("Pre-roll");
+    if (videoRef) videoRef.play();
+    try {
+      await RecordAudioWithOptions(
+        charId,
+        adapters.RecordOptions.createFrom({
+          timecode: inPoint,
+          punch: true,
+          inPoint,
+          outPoint,
+          preRoll,
+          postRoll: PUNCH_POST_ROLL,
+        }),
+      );
+    } finally {
+      if (videoRef) videoRef.pause();
+      setPunchStatus("");
+      setRecordingStartTime(undefined);
+      await loadProject();
+    }
+  };
+
   const cancelCountdown = () => {
     setShowCountdown(false);
   };
@@ -247,12 +331,121 @@ export function ProjectEditor(props: ProjectEditorProps) {
     }
   };
 
+  // exportVolumes applies the mixer to an export: muted characters are
+  // left out and the rest keep their track volume.
+  const exportVolumes = () => {
+    const volumes: Record<string, number> = {};
+    for (const char of project()?.characters ?? []) {
+      volumes[char.id] = mutedCharacters()[char.id]
+        ? 0
+        : (characterVolumes()[char.id] ?? 1);
+    }
+    return volumes;
+  };
+
+  const handleExportMode = async (
+    mode: string,
+    format: string,
+    extra: Partial<adapters.ExportOptions> = {},
+  ) => {
+    setShowExportMenu(false);
+    try {
+      const path = await ExportRecordingsWithOptions(
+        adapters.ExportOptions.createFrom({
+          format,
+          mode,
+          masterVolume: masterVolume(),
+          characterVolumes: exportVolumes(),
+          ...extra,
+        }),
+      );
+      if (path) {
+        alert(`Exported to: ${path}`);
+      }
+    } catch (e) {
+      alert(`Export failed: ${e}`);
+    }
+  };
+
+  const handleExportSubtitles = async (format: string) => {
+    setShowExportMenu(false);
+    const path = await ExportSubtitles(format);
+    if (path) {
+      alert(`Exported to: ${path}`);
+    }
+  };
+
+  const handleExportCueSheet = async (format: string) => {
+    setShowExportMenu(false);
+    try {
+      const path = await ExportCueSheet(format);
+      if (path) {
+        alert(`Exported to: ${path}`);
+      }
+    } catch (e) {
+      alert(`Cue sheet export failed: ${e}`);
+    }
+  };
+
   const handleDeviceSelect = async (device: core.DeviceInfo) => {
     await SelectDevice(device.id);
     setSelectedDevice(device);
     setShowDeviceMenu(false);
   };
 
+  const handleFormatChange = async (key: string, value: number) => {
+    const current = project()?.audio_format;
+    if (!current) return;
+    await SetAudioFormat(core.AudioFormat.createFrom({ ...current, [key]: value }));
+    await loadProject();
+  };
+
+  const handleImportSubtitles = async () => {
+    const replace =
+      (project()?.cues?.length ?? 0) > 0 &&
+      confirm("Replace the existing cues? Cancel adds to them instead.");
+    await ImportSubtitles(replace);
+    await loadProject();
+  };
+
+  const handleImportScript = async () => {
+    const replace =
+      (project()?.cues?.length ?? 0) > 0 &&
+      confirm("Replace the existing cues? Cancel adds to them instead.");
+    await ImportScript(replace);
+    await loadProject();
+  };
+
+  const currentCue = createMemo(() =>
+    project()?.cues?.find(
+      (c) => !c.unplaced && currentTime() >= c.start && currentTime() < c.end,
+    ),
+  );
+
+  const nextCue = createMemo(() =>
+    project()?.cues?.find((c) => !c.unplaced && c.start > currentTime() + 0.01),
+  );
+
+  const unplacedCues = createMemo(() =>
+    (project()?.cues || []).filter((c) => c.unplaced),
+  );
+
+  const handleCueDrop = async (e: DragEvent) => {
+    const cueId = e.dataTransfer?.getData("application/x-viover-cue");
+    if (!cueId || !trackContainerRef) return;
+    e.preventDefault();
+    const rect = trackContainerRef.getBoundingClientRect();
+    const x = e.clientX - rect.left - sidebarWidth();
+    const time = Math.max(0, Math.min(x / pixelsPerSecond(), duration()));
+    await PlaceCue(cueId, time);
+    await loadProject();
+  };
+
+  const goToCue = (cue: core.Cue) => {
+    handleSeek(cue.start);
+    if (cue.character_id) setSelectedCharacterId(cue.character_id);
+  };
+
   const handleLoadedMetadata = () => {
     if (!videoRef) return;
     setDuration(videoRef.duration);
@@ -368,6 +561,20 @@ export function ProjectEditor(props: ProjectEditorProps) {
         e.preventDefault();
         handleAddCharacter();
         break;
+      case "KeyI":
+        e.preventDefault();
+        setPunchIn(currentTime());
+        break;
+      case "KeyO":
+        e.preventDefault();
+        setPunchOut(currentTime());
+        break;
+      case "KeyP":
+        if (selectedCharacterId() && punchReady()) {
+          e.preventDefault();
+          handlePunchRecord();
+        }
+        break;
       case "ArrowLeft":
         e.preventDefault();
         handleSeek(
@@ -425,6 +632,37 @@ export function ProjectEditor(props: ProjectEditorProps) {
     setIsRecording(true);
   });
 
+  EventsOn("devices-changed", (data: { devices: core.DeviceInfo[] }) => {
+    setDevices(data.devices || []);
+  });
+
+  EventsOn(
+    "device-fallback",
+    (data: { lost: core.DeviceInfo; selected: core.DeviceInfo; interrupted: boolean }) => {
+      setSelectedDevice(data.selected?.id ? data.selected : null);
+      const next = data.selected?.devices_name || "the system default";
+      const action = data.interrupted ? "Recording stopped. " : "";
+      alert(`${data.lost.devices_name} was disconnected. ${action}Switched to ${next}.`);
+    },
+  );
+
+  EventsOn("punch-countdown", (data: { remaining: number }) => {
+    setPunchStatus(`Punch in ${data.remaining}`);
+  });
+
+  EventsOn("punch-in", () => {
+    setPunchStatus("Recording");
+  });
+
+  EventsOn("punch-out", () => {
+    setPunchStatus("Post-roll");
+  });
+
+  EventsOn("punch-finished", () => {
+    setPunchStatus("");
+    if (videoRef) videoRef.pause();
+  });
+
   EventsOn("recording-stopped", () => {
     setIsRecording(false);
     setRecordingStartTime(undefined);
@@ -435,6 +673,7 @@ export function ProjectEditor(props: ProjectEditorProps) {
   onMount(() => {
     loadProject();
     loadDevices();
+    loadMasterVolume();
     window.addEventListener("keydown", handleKeyDown);
   });
 
@@ -510,6 +749,7 @@ export function ProjectEditor(props: ProjectEditorProps) {
             step="0.05"
             value={masterVolume()}
             onInput={(e) => setMasterVolume(parseFloat(e.currentTarget.value))}
+            onChange={(e) => saveMasterVolume(parseFloat(e.currentTarget.value))}
             class="w-20 h-1 accent-[#00ff88] cursor-pointer"
             title={`Master Volume: ${Math.round(masterVolume() * 100)}%`}
           />
@@ -616,6 +856,29 @@ export function ProjectEditor(props: ProjectEditorProps) {
                   </button>
                 )}
               </For>
+              <div class="border-t border-white/40 mt-2 pt-2 px-4 flex flex-col gap-1.5">
+                <For each={FORMAT_OPTIONS}>
+                  {(option) => (
+                    <div class="flex gap-1.5">
+                      <For each={option.values}>
+                        {([value, label]) => (
+                          <button
+                            onClick={() => handleFormatChange(option.key, value)}
+                            disabled={isRecording()}
+                            class={`aero-button flex-1 px-2 py-1 text-xs disabled:opacity-40 ${
+                              project()?.audio_format?.[option.key] === value
+                                ? "text-[#004d40] font-bold"
+                                : "text-slate-800"
+                            }`}
+                          >
+                            {label}
+                          </button>
+                        )}
+                      </For>
+                    </div>
+                  )}
+                </For>
+              </div>
             </div>
           </Show>
         </div>
@@ -643,7 +906,7 @@ export function ProjectEditor(props: ProjectEditorProps) {
             </span>
           </button>
           <Show when={showExportMenu()}>
-            <div class="aero-dropdown absolute right-0 mt-2 w-36 py-2 z-50">
+            <div class="aero-dropdown absolute right-0 mt-2 w-56 max-h-[70vh] overflow-y-auto py-2 z-50">
               <button
                 onClick={() => handleExport("wav")}
                 class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
@@ -662,6 +925,67 @@ export function ProjectEditor(props: ProjectEditorProps) {
               >
                 FLAC
               </button>
+              <div class="border-t border-white/40 my-1" />
+              <For each={["wav", "mp3", "flac"]}>
+                {(format) => (
+                  <button
+                    onClick={() => handleExportMode("mixdown", format)}
+                    class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
+                  >
+                    {format.toUpperCase()} mixdown
+                  </button>
+                )}
+              </For>
+              <For each={["wav", "flac"]}>
+                {(format) => (
+                  <button
+                    onClick={() => handleExportMode("stems", format)}
+                    class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
+                  >
+                    {format.toUpperCase()} stems
+                  </button>
+                )}
+              </For>
+              <Show when={project()?.video}>
+                <div class="border-t border-white/40 my-1" />
+                <For each={VIDEO_EXPORTS}>
+                  {(item) => (
+                    <button
+                      onClick={() =>
+                        handleExportMode("video", "wav", {
+                          videoAudio: item.audio,
+                          originalLevelDb: ORIGINAL_DUCK_DB,
+                        })
+                      }
+                      class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
+                    >
+                      {item.label}
+                    </button>
+                  )}
+                </For>
+              </Show>
+              <div class="border-t border-white/40 my-1" />
+              <For each={["srt", "vtt", "ass"]}>
+                {(format) => (
+                  <button
+                    onClick={() => handleExportSubtitles(format)}
+                    class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
+                  >
+                    {format.toUpperCase()} subtitles
+                  </button>
+                )}
+              </For>
+              <div class="border-t border-white/40 my-1" />
+              <For each={["csv", "html", "pdf"]}>
+                {(format) => (
+                  <button
+                    onClick={() => handleExportCueSheet(format)}
+                    class="aero-dropdown-item w-full px-4 py-2.5 text-left text-sm text-slate-800"
+                  >
+                    {format.toUpperCase()} cue sheet
+                  </button>
+                )}
+              </For>
             </div>
           </Show>
         </div>
@@ -692,6 +1016,14 @@ export function ProjectEditor(props: ProjectEditorProps) {
                 <span class="aero-badge">C</span>
                 <span class="text-slate-800">Add Character</span>
               </div>
+              <div class="flex items-center gap-3">
+                <span class="aero-badge">I / O</span>
+                <span class="text-slate-800">Set Punch In / Out</span>
+              </div>
+              <div class="flex items-center gap-3">
+                <span class="aero-badge">P</span>
+                <span class="text-slate-800">Punch Record</span>
+              </div>
               <div class="flex items-center gap-3">
                 <span class="aero-badge">Left/Right</span>
                 <span class="text-slate-800">Seek 5s</span>
@@ -817,6 +1149,72 @@ export function ProjectEditor(props: ProjectEditorProps) {
           </Show>
         </div>
 
+        <Show when={project()?.cues?.length}>
+          <div class="shrink-0 flex items-center gap-3 px-4 py-2 aero-glass">
+            <div class="flex-1 min-w-0 text-sm text-slate-800">
+              <Show
+                when={currentCue()}
+                fallback={<span class="text-slate-600 italic">No cue at the playhead</span>}
+              >
+                {(cue) => (
+                  <span>
+                    <span
+                      class="font-bold mr-2"
+                      style={{
+                        color: project()?.characters.find((c) => c.id === cue().character_id)?.color,
+                      }}
+                    >
+                      {cue().speaker || "—"}
+                    </span>
+                    <span class="whitespace-pre-line">{cue().text}</span>
+                  </span>
+                )}
+              </Show>
+            </div>
+            <Show when={nextCue()}>
+              {(cue) => (
+                <button
+                  onClick={() => goToCue(cue())}
+                  class="aero-button px-3 py-1.5 text-xs text-slate-800 shrink-0 max-w-64 truncate"
+                >
+                  Next: {cue().speaker ? `${cue().speaker} — ` : ""}
+                  {cue().text}
+                </button>
+              )}
+            </Show>
+          </div>
+        </Show>
+
+        <Show when={unplacedCues().length}>
+          <div class="shrink-0 flex items-center gap-2 px-4 py-2 aero-glass overflow-x-auto">
+            <span class="text-xs text-slate-600 shrink-0">
+              Drag onto the timeline:
+            </span>
+            <For each={unplacedCues()}>
+              {(cue) => (
+                <div
+                  draggable="true"
+                  onDragStart={(e) =>
+                    e.dataTransfer?.setData("application/x-viover-cue", cue.id)
+                  }
+                  title={cue.text}
+                  class="aero-button px-3 py-1.5 text-xs text-slate-800 shrink-0 max-w-64 truncate cursor-grab"
+                >
+                  <span
+                    class="font-bold mr-1"
+                    style={{
+                      color: project()?.characters.find((c) => c.id === cue.character_id)?.color,
+                    }}
+                  >
+                    {cue.speaker}
+                  </span>
+                  {cue.text}
+                </div>
+              )}
+            </For>
+          </div>
+        </Show>
+
         <Show when={videoUrl()}>
           <div class="shrink-0 aero-timeline overflow-visible">
             <div class="flex items-center justify-between gap-3 px-4 py-3 border-b border-white/5">
@@ -827,6 +1225,18 @@ export function ProjectEditor(props: ProjectEditorProps) {
                 duration={duration()}
                 onPlayPause={togglePlayPause}
               />
+              <button
+                onClick={handleImportSubtitles}
+                class="aero-button px-4 py-2 text-sm font-medium text-slate-800"
+              >
+                Import Cues
+              </button>
+              <button
+                onClick={handleImportScript}
+                class="aero-button px-4 py-2 text-sm font-medium text-slate-800"
+              >
+                Import Script
+              </button>
               <button
                 onClick={handleAddCharacter}
                 class="aero-button aero-button-primary px-4 py-2 text-sm font-semibold"
@@ -897,6 +1307,22 @@ export function ProjectEditor(props: ProjectEditorProps) {
                     <span class="aero-badge ml-1">R</span>
                   </span>
                 </button>
+                <button
+                  onClick={handlePunchRecord}
+                  disabled={isRecording() || !punchReady()}
+                  title="Set the in and out points with I and O"
+                  class="aero-button px-4 py-2 text-sm font-medium text-slate-800 disabled:opacity-50"
+                >
+                  <span class="flex items-center gap-2">
+                    Punch
+                    <span class="aero-badge ml-1">P</span>
+                  </span>
+                </button>
+                <Show when={punchStatus()}>
+                  <span class="text-sm font-semibold text-slate-800">
+                    {punchStatus()}
+                  </span>
+                </Show>
               </Show>
               <Show
                 when={
@@ -939,7 +1365,22 @@ export function ProjectEditor(props: ProjectEditorProps) {
                   ref={trackContainerRef}
                   class="relative"
                   onClick={handleTrackClick}
+                  onDragOver={(e) => {
+                    if (e.dataTransfer?.types.includes("application/x-viover-cue")) {
+                      e.preventDefault();
+                    }
+                  }}
+                  onDrop={handleCueDrop}
                 >
+                  <Show when={punchReady()}>
+                    <div
+                      class="absolute top-0 bottom-0 bg-red-500/10 border-x border-red-500/60 pointer-events-none z-10"
+                      style={{
+                        left: `${sidebarWidth() + punchIn()! * pixelsPerSecond()}px`,
+                        width: `${(punchOut()! - punchIn()!) * pixelsPerSecond()}px`,
+                      }}
+                    />
+                  </Show>
                   <For each={project()?.characters || []}>
                     {(char) => (
                       <CharacterTrack
@@ -5,6 +5,8 @@ import {
   CreateProject,
   DeleteProject,
   UpdateProjectTitle,
+  RediscoverProjects,
+  CheckProjects,
 } from "../../wailsjs/go/adapters/App";
 
 interface ProjectListProps {
@@ -44,6 +46,22 @@ export function ProjectList(props: ProjectListProps) {
     await loadProjects();
   };
 
+  const handleCheck = async () => {
+    const list = await CheckProjects();
+    setProjects(list || []);
+  };
+
+  const handleRediscover = async () => {
+    await RediscoverProjects();
+    await loadProjects();
+  };
+
+  const statusLabels: Record<string, string> = {
+    missing: "Missing",
+    moved: "Moved",
+    corrupt: "Corrupt",
+  };
+
   const startEdit = (p: core.ProjectMeta) => {
     setEditingId(p.id);
     setEditTitle(p.title);
@@ -124,7 +142,12 @@ export function ProjectList(props: ProjectListProps) {
                           class="flex-1 cursor-pointer min-w-0"
                           onClick={() => props.onOpenProject(p.id)}
                         >
-                          <div class="font-semibold text-slate-800 text-lg truncate">{p.title}</div>
+                          <div class="flex items-center gap-2">
+                            <div class="font-semibold text-slate-800 text-lg truncate">{p.title}</div>
+                            <Show when={p.status && p.status !== "ok"}>
+                              <span class="aero-badge text-red-600">{statusLabels[p.status!] ?? p.status}</span>
+                            </Show>
+                          </div>
                           <div class="text-sm text-slate-700 truncate mt-0.5">{p.path}</div>
                         </div>
                         <div class="flex items-center gap-2 opacity-0 group-hover:opacity-100 transition-opacity">
@@ -184,6 +207,21 @@ export function ProjectList(props: ProjectListProps) {
           </div>
         </div>
 
+        <div class="mt-6 flex justify-center gap-3">
+          <button
+            onClick={handleCheck}
+            class="aero-button px-5 py-2 text-slate-800 text-sm font-medium"
+          >
+            Check projects
+          </button>
+          <button
+            onClick={handleRediscover}
+            class="aero-button px-5 py-2 text-slate-800 text-sm font-medium"
+          >
+            Find projects in folder...
+          </button>
+        </div>
+
         <div class="mt-8 text-center text-slate-600 text-xs">
           <span class="aero-badge">Space</span> Play/Pause
           <span class="mx-2">|</span>
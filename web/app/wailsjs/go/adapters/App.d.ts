@@ -5,6 +5,8 @@ import {adapters} from '../models';
 
 export function AddCharacter(arg1:string,arg2:string):Promise<core.Character>;
 
+export function CheckProjects():Promise<Array<core.ProjectMeta>>;
+
 export function CloseProject():Promise<void>;
 
 export function CreateProject(arg1:string):Promise<core.Project>;
@@ -15,40 +17,90 @@ export function DeleteProject(arg1:string):Promise<void>;
 
 export function DeleteRecording(arg1:string):Promise<void>;
 
+export function ExportCueSheet(arg1:string):Promise<string>;
+
+export function ExportProjectBundle(arg1:core.BundleOptions):Promise<string>;
+
 export function ExportRecordings(arg1:string):Promise<string>;
 
 export function ExportRecordingsWithOptions(arg1:adapters.ExportOptions):Promise<string>;
 
-export function GetAudioData(arg1:string):Promise<string>;
+export function ExportSubtitles(arg1:string):Promise<string>;
+
+export function FormatTimecode(arg1:number):Promise<string>;
+
+export function GetAudioURL(arg1:string):Promise<string>;
 
 export function GetCurrentProject():Promise<core.Project>;
 
 export function GetMicrophoneGain():Promise<number>;
 
+export function GetMissingMedia():Promise<Array<core.MissingMedia>>;
+
+export function GetPreferences():Promise<core.Preferences>;
+
 export function GetRecordingWaveform(arg1:string):Promise<Array<number>>;
 
 export function GetSelectedDevice():Promise<core.DeviceInfo>;
 
+export function GetTakes(arg1:string):Promise<Array<core.Recording>>;
+
 export function GetVideoURL():Promise<string>;
 
+export function History():Promise<core.HistoryState>;
+
+export function ImportProjectBundle(arg1:boolean):Promise<core.Project>;
+
+export function ImportScript(arg1:boolean):Promise<Array<core.Cue>>;
+
+export function ImportSubtitles(arg1:boolean):Promise<Array<core.Cue>>;
+
 export function ListDevices():Promise<Array<core.DeviceInfo>>;
 
+export function ListProjectBackups():Promise<Array<core.ProjectBackup>>;
+
 export function ListProjects():Promise<Array<core.ProjectMeta>>;
 
 export function OpenProject(arg1:string):Promise<core.Project>;
 
+export function ParseTimecode(arg1:string):Promise<number>;
+
+export function PlaceCue(arg1:string,arg2:number):Promise<void>;
+
 export function RecordAudio(arg1:string,arg2:number):Promise<core.Recording>;
 
+export function RecordAudioWithOptions(arg1:string,arg2:adapters.RecordOptions):Promise<core.Recording>;
+
+export function RediscoverProjects():Promise<Array<core.ProjectMeta>>;
+
+export function Redo():Promise<core.Project>;
+
+export function RelinkMedia():Promise<core.RelinkResult>;
+
+export function RelocateProject(arg1:string):Promise<core.Project>;
+
+export function RestoreProjectBackup(arg1:number):Promise<core.Project>;
+
 export function SelectDevice(arg1:string):Promise<core.DeviceInfo>;
 
 export function SelectVideo():Promise<core.Video>;
 
+export function SetActiveTake(arg1:string):Promise<void>;
+
+export function SetAudioFormat(arg1:core.AudioFormat):Promise<void>;
+
 export function SetMicrophoneGain(arg1:number):Promise<void>;
 
+export function SetStartTimecode(arg1:string):Promise<void>;
+
 export function StopRecording():Promise<void>;
 
+export function Undo():Promise<core.Project>;
+
 export function UpdateCharacter(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function UpdatePreferences(arg1:core.Preferences):Promise<void>;
+
 export function UpdateProjectTitle(arg1:string,arg2:string):Promise<void>;
 
 export function UpdateRecordingGain(arg1:string,arg2:number):Promise<void>;
@@ -6,6 +6,10 @@ export function AddCharacter(arg1, arg2) {
   return window['go']['adapters']['App']['AddCharacter'](arg1, arg2);
 }
 
+export function CheckProjects() {
+  return window['go']['adapters']['App']['CheckProjects']();
+}
+
 export function CloseProject() {
   return window['go']['adapters']['App']['CloseProject']();
 }
@@ -26,6 +30,14 @@ export function DeleteRecording(arg1) {
   return window['go']['adapters']['App']['DeleteRecording'](arg1);
 }
 
+export function ExportCueSheet(arg1) {
+  return window['go']['adapters']['App']['ExportCueSheet'](arg1);
+}
+
+export function ExportProjectBundle(arg1) {
+  return window['go']['adapters']['App']['ExportProjectBundle'](arg1);
+}
+
 export function ExportRecordings(arg1) {
   return window['go']['adapters']['App']['ExportRecordings'](arg1);
 }
@@ -34,8 +46,16 @@ export function ExportRecordingsWithOptions(arg1) {
   return window['go']['adapters']['App']['ExportRecordingsWithOptions'](arg1);
 }
 
-export function GetAudioData(arg1) {
-  return window['go']['adapters']['App']['GetAudioData'](arg1);
+export function ExportSubtitles(arg1) {
+  return window['go']['adapters']['App']['ExportSubtitles'](arg1);
+}
+
+export function FormatTimecode(arg1) {
+  return window['go']['adapters']['App']['FormatTimecode'](arg1);
+}
+
+export function GetAudioURL(arg1) {
+  return window['go']['adapters']['App']['GetAudioURL'](arg1);
 }
 
 export function GetCurrentProject() {
@@ -46,6 +66,14 @@ export function GetMicrophoneGain() {
   return window['go']['adapters']['App']['GetMicrophoneGain']();
 }
 
+export function GetMissingMedia() {
+  return window['go']['adapters']['App']['GetMissingMedia']();
+}
+
+export function GetPreferences() {
+  return window['go']['adapters']['App']['GetPreferences']();
+}
+
 export function GetRecordingWaveform(arg1) {
   return window['go']['adapters']['App']['GetRecordingWaveform'](arg1);
 }
@@ -54,14 +82,38 @@ export function GetSelectedDevice() {
   return window['go']['adapters']['App']['GetSelectedDevice']();
 }
 
+export function GetTakes(arg1) {
+  return window['go']['adapters']['App']['GetTakes'](arg1);
+}
+
 export function GetVideoURL() {
   return window['go']['adapters']['App']['GetVideoURL']();
 }
 
+export function History() {
+  return window['go']['adapters']['App']['History']();
+}
+
+export function ImportProjectBundle(arg1) {
+  return window['go']['adapters']['App']['ImportProjectBundle'](arg1);
+}
+
+export function ImportScript(arg1) {
+  return window['go']['adapters']['App']['ImportScript'](arg1);
+}
+
+export function ImportSubtitles(arg1) {
+  return window['go']['adapters']['App']['ImportSubtitles'](arg1);
+}
+
 export function ListDevices() {
   return window['go']['adapters']['App']['ListDevices']();
 }
 
+export function ListProjectBackups() {
+  return window['go']['adapters']['App']['ListProjectBackups']();
+}
+
 export function ListProjects() {
   return window['go']['adapters']['App']['ListProjects']();
 }
@@ -70,10 +122,42 @@ export function OpenProject(arg1) {
   return window['go']['adapters']['App']['OpenProject'](arg1);
 }
 
+export function ParseTimecode(arg1) {
+  return window['go']['adapters']['App']['ParseTimecode'](arg1);
+}
+
+export function PlaceCue(arg1, arg2) {
+  return window['go']['adapters']['App']['PlaceCue'](arg1, arg2);
+}
+
 export function RecordAudio(arg1, arg2) {
   return window['go']['adapters']['App']['RecordAudio'](arg1, arg2);
 }
 
+export function RecordAudioWithOptions(arg1, arg2) {
+  return window['go']['adapters']['App']['RecordAudioWithOptions'](arg1, arg2);
+}
+
+export function RediscoverProjects() {
+  return window['go']['adapters']['App']['RediscoverProjects']();
+}
+
+export function Redo() {
+  return window['go']['adapters']['App']['Redo']();
+}
+
+export function RelinkMedia() {
+  return window['go']['adapters']['App']['RelinkMedia']();
+}
+
+export function RelocateProject(arg1) {
+  return window['go']['adapters']['App']['RelocateProject'](arg1);
+}
+
+export function RestoreProjectBackup(arg1) {
+  return window['go']['adapters']['App']['RestoreProjectBackup'](arg1);
+}
+
 export function SelectDevice(arg1) {
   return window['go']['adapters']['App']['SelectDevice'](arg1);
 }
@@ -82,18 +166,38 @@ export function SelectVideo() {
   return window['go']['adapters']['App']['SelectVideo']();
 }
 
+export function SetActiveTake(arg1) {
+  return window['go']['adapters']['App']['SetActiveTake'](arg1);
+}
+
+export function SetAudioFormat(arg1) {
+  return window['go']['adapters']['App']['SetAudioFormat'](arg1);
+}
+
 export function SetMicrophoneGain(arg1) {
   return window['go']['adapters']['App']['SetMicrophoneGain'](arg1);
 }
 
+export function SetStartTimecode(arg1) {
+  return window['go']['adapters']['App']['SetStartTimecode'](arg1);
+}
+
 export function StopRecording() {
   return window['go']['adapters']['App']['StopRecording']();
 }
 
+export function Undo() {
+  return window['go']['adapters']['App']['Undo']();
+}
+
 export function UpdateCharacter(arg1, arg2, arg3) {
   return window['go']['adapters']['App']['UpdateCharacter'](arg1, arg2, arg3);
 }
 
+export function UpdatePreferences(arg1) {
+  return window['go']['adapters']['App']['UpdatePreferences'](arg1);
+}
+
 export function UpdateProjectTitle(arg1, arg2) {
   return window['go']['adapters']['App']['UpdateProjectTitle'](arg1, arg2);
 }
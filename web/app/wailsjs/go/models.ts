@@ -3,7 +3,11 @@ export namespace adapters {
 	export class ExportOptions {
 	    format: string;
 	    characterVolumes: Record<string, number>;
-	    masterVolume: number;
+	    masterVolume?: number;
+	    takes: string;
+	    mode: string;
+	    videoAudio: string;
+	    originalLevelDb: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ExportOptions(source);
@@ -14,6 +18,32 @@ export namespace adapters {
 	        this.format = source["format"];
 	        this.characterVolumes = source["characterVolumes"];
 	        this.masterVolume = source["masterVolume"];
+	        this.takes = source["takes"];
+	        this.mode = source["mode"];
+	        this.videoAudio = source["videoAudio"];
+	        this.originalLevelDb = source["originalLevelDb"];
+	    }
+	}
+	export class RecordOptions {
+	    timecode: number;
+	    punch: boolean;
+	    inPoint: number;
+	    outPoint: number;
+	    preRoll: number;
+	    postRoll: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timecode = source["timecode"];
+	        this.punch = source["punch"];
+	        this.inPoint = source["inPoint"];
+	        this.outPoint = source["outPoint"];
+	        this.preRoll = source["preRoll"];
+	        this.postRoll = source["postRoll"];
 	    }
 	}
 
@@ -21,6 +51,50 @@ export namespace adapters {
 
 export namespace core {
 	
+	export class AudioFormat {
+	    sample_rate: number;
+	    bit_depth: number;
+	    channels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioFormat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sample_rate = source["sample_rate"];
+	        this.bit_depth = source["bit_depth"];
+	        this.channels = source["channels"];
+	    }
+	}
+	export class AudioStream {
+	    codec: string;
+	    channels: number;
+	    sample_rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioStream(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.codec = source["codec"];
+	        this.channels = source["channels"];
+	        this.sample_rate = source["sample_rate"];
+	    }
+	}
+	export class BundleOptions {
+	    includeVideo: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.includeVideo = source["includeVideo"];
+	    }
+	}
 	export class Character {
 	    id: string;
 	    name: string;
@@ -37,6 +111,30 @@ export namespace core {
 	        this.color = source["color"];
 	    }
 	}
+	export class Cue {
+	    id: string;
+	    character_id: string;
+	    speaker?: string;
+	    start: number;
+	    end: number;
+	    text: string;
+	    unplaced?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Cue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.character_id = source["character_id"];
+	        this.speaker = source["speaker"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.text = source["text"];
+	        this.unplaced = source["unplaced"];
+	    }
+	}
 	export class DeviceInfo {
 	    devices_name: string;
 	    id: string;
@@ -51,14 +149,72 @@ export namespace core {
 	        this.id = source["id"];
 	    }
 	}
+	export class HistoryState {
+	    undo: string[];
+	    redo: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.undo = source["undo"];
+	        this.redo = source["redo"];
+	    }
+	}
+	export class MissingMedia {
+	    kind: string;
+	    id: string;
+	    file_path: string;
+	    hash?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MissingMedia(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.id = source["id"];
+	        this.file_path = source["file_path"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class Preferences {
+	    microphone_name: string;
+	    input_gain_db: number;
+	    export_format: string;
+	    master_volume: number;
+	    export_dir: string;
+	    project_root: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preferences(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.microphone_name = source["microphone_name"];
+	        this.input_gain_db = source["input_gain_db"];
+	        this.export_format = source["export_format"];
+	        this.master_volume = source["master_volume"];
+	        this.export_dir = source["export_dir"];
+	        this.project_root = source["project_root"];
+	    }
+	}
 	export class Recording {
 	    id: string;
 	    character_id: string;
 	    file_path: string;
+	    hash?: string;
 	    timecode: number;
 	    duration: number;
 	    volume: number;
 	    gain_db: number;
+	    slot_id: string;
+	    take: number;
+	    active: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Recording(source);
@@ -69,17 +225,30 @@ export namespace core {
 	        this.id = source["id"];
 	        this.character_id = source["character_id"];
 	        this.file_path = source["file_path"];
+	        this.hash = source["hash"];
 	        this.timecode = source["timecode"];
 	        this.duration = source["duration"];
 	        this.volume = source["volume"];
 	        this.gain_db = source["gain_db"];
+	        this.slot_id = source["slot_id"];
+	        this.take = source["take"];
+	        this.active = source["active"];
 	    }
 	}
 	export class Video {
 	    id: string;
 	    file_name: string;
 	    file_path: string;
+	    hash?: string;
 	    thumbnail: string;
+	    duration: number;
+	    frame_rate: number;
+	    frame_rate_num: number;
+	    frame_rate_den: number;
+	    width: number;
+	    height: number;
+	    codec: string;
+	    audio_streams: AudioStream[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Video(source);
@@ -90,16 +259,47 @@ export namespace core {
 	        this.id = source["id"];
 	        this.file_name = source["file_name"];
 	        this.file_path = source["file_path"];
+	        this.hash = source["hash"];
 	        this.thumbnail = source["thumbnail"];
+	        this.duration = source["duration"];
+	        this.frame_rate = source["frame_rate"];
+	        this.frame_rate_num = source["frame_rate_num"];
+	        this.frame_rate_den = source["frame_rate_den"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.codec = source["codec"];
+	        this.audio_streams = this.convertValues(source["audio_streams"], AudioStream);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class Project {
+	    schema_version: number;
 	    id: string;
 	    title: string;
 	    path: string;
+	    start_timecode?: string;
+	    audio_format: AudioFormat;
 	    video?: Video;
 	    characters: Character[];
 	    recordings: Recording[];
+	    cues: Cue[];
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
@@ -111,12 +311,16 @@ export namespace core {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schema_version = source["schema_version"];
 	        this.id = source["id"];
 	        this.title = source["title"];
 	        this.path = source["path"];
+	        this.start_timecode = source["start_timecode"];
+	        this.audio_format = this.convertValues(source["audio_format"], AudioFormat);
 	        this.video = this.convertValues(source["video"], Video);
 	        this.characters = this.convertValues(source["characters"], Character);
 	        this.recordings = this.convertValues(source["recordings"], Recording);
+	        this.cues = this.convertValues(source["cues"], Cue);
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	    }
@@ -139,12 +343,46 @@ export namespace core {
 		    return a;
 		}
 	}
+	export class ProjectBackup {
+	    index: number;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectBackup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ProjectMeta {
 	    id: string;
 	    title: string;
 	    path: string;
 	    created_at?: string;
 	    updated_at?: string;
+	    status?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ProjectMeta(source);
@@ -157,9 +395,42 @@ export namespace core {
 	        this.path = source["path"];
 	        this.created_at = source["created_at"];
 	        this.updated_at = source["updated_at"];
+	        this.status = source["status"];
 	    }
 	}
 	
+	export class RelinkResult {
+	    relinked: MissingMedia[];
+	    missing: MissingMedia[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RelinkResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relinked = this.convertValues(source["relinked"], MissingMedia);
+	        this.missing = this.convertValues(source["missing"], MissingMedia);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 